@@ -2,12 +2,15 @@ package agent
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/livechat/lc-sdk-go/v6/authorization"
 	i "github.com/livechat/lc-sdk-go/v6/internal"
+	"github.com/livechat/lc-sdk-go/v6/internal/images"
 )
 
 type agentAPI interface {
@@ -24,6 +27,7 @@ type agentAPI interface {
 // See this package's package overview docs for details on the service.
 type API struct {
 	agentAPI
+	eventValidation bool
 }
 
 // NewAPI returns ready to use Agent API.
@@ -34,7 +38,7 @@ func NewAPI(t authorization.TokenGetter, client *http.Client, clientID string) (
 	if err != nil {
 		return nil, err
 	}
-	return &API{api}, nil
+	return &API{agentAPI: api, eventValidation: true}, nil
 }
 
 // SetAuthorID provides a way to point the actual author of the action (e.g. send an event as a bot)
@@ -42,6 +46,36 @@ func (a *API) SetAuthorID(authorID string) {
 	a.agentAPI.SetCustomHeader("X-Author-Id", authorID)
 }
 
+// SetEventValidation allows to turn off (or back on) validation of event fields performed by SendEvent,
+// StartChat and ResumeChat before the request is sent. Check of supported event types is always performed.
+//
+// Validation is enabled by default, see ValidateEventFields for details.
+func (a *API) SetEventValidation(enabled bool) {
+	a.eventValidation = enabled
+}
+
+func (a *API) validateEvent(e interface{}) error {
+	if !a.eventValidation {
+		return ValidateEvent(e)
+	}
+	return ValidateEventFields(e)
+}
+
+func (a *API) validateInitialChat(chat *InitialChat) error {
+	if err := chat.Validate(); err != nil {
+		return err
+	}
+	if !a.eventValidation || chat.Thread == nil {
+		return nil
+	}
+	for _, e := range chat.Thread.Events {
+		if err := ValidateEventFields(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ListChats returns chat summaries list.
 func (a *API) ListChats(filters *chatsFilters, sortOrder, pageID string, limit uint) (summary []ChatSummary, found uint, previousPage, nextPage string, err error) {
 	var resp listChatsResponse
@@ -104,7 +138,7 @@ func (a *API) ListArchives(filters *archivesFilters, pageID string, limit uint)
 func (a *API) StartChat(initialChat *InitialChat, continuous, active bool) (chatID, threadID string, eventIDs []string, err error) {
 	var resp startChatResponse
 
-	if err := initialChat.Validate(); err != nil {
+	if err := a.validateInitialChat(initialChat); err != nil {
 		return "", "", nil, err
 	}
 
@@ -122,7 +156,7 @@ func (a *API) StartChat(initialChat *InitialChat, continuous, active bool) (chat
 func (a *API) ResumeChat(initialChat *InitialChat, continuous, active bool) (threadID string, eventIDs []string, err error) {
 	var resp resumeChatResponse
 
-	if err := initialChat.Validate(); err != nil {
+	if err := a.validateInitialChat(initialChat); err != nil {
 		return "", nil, err
 	}
 
@@ -200,9 +234,10 @@ func (a *API) RemoveUserFromChat(chatID, userID, userType string, ignoreRequeste
 // SendEvent sends event of supported type to given chat.
 // It returns event ID.
 //
-// Supported event types are: event, message, system_message and file.
+// Supported event types are: event, custom, file, form, message, rich_message and system_message.
+// Unless disabled with SetEventValidation, event fields are validated before sending with ValidateEventFields.
 func (a *API) SendEvent(chatID string, event interface{}, attachToLastThread bool) (string, error) {
-	if err := ValidateEvent(event); err != nil {
+	if err := a.validateEvent(event); err != nil {
 		return "", err
 	}
 
@@ -216,6 +251,55 @@ func (a *API) SendEvent(chatID string, event interface{}, attachToLastThread boo
 	return resp.EventID, err
 }
 
+// SendFile uploads file with given name and content read from r, then sends it to given chat as file event.
+// It returns event ID.
+//
+// Content type is detected from the file's content and name, and for PNG, JPEG and GIF images their width
+// and height are filled in. Uploaded file is sent immediately, as its URL expires after about 24 hours.
+//
+// Files larger than 10 MB are rejected. File event is validated with ValidateEventFields before the file
+// is uploaded, so invalid options don't leave unused uploads behind.
+func (a *API) SendFile(chatID, name string, r io.Reader, opts *SendFileOptions) (string, error) {
+	if opts == nil {
+		opts = &SendFileOptions{}
+	}
+	data, err := i.ReadFile(r)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read file %q: %w", name, err)
+	}
+
+	f := &File{
+		Event: Event{
+			Type:       "file",
+			Visibility: opts.Visibility,
+		},
+		ContentType:     opts.ContentType,
+		Name:            name,
+		Size:            len(data),
+		AlternativeText: opts.AlternativeText,
+	}
+	if f.ContentType == "" {
+		f.ContentType = i.DetectContentType(name, data)
+	}
+	if width, height, ok := images.Size(data); ok {
+		f.Width, f.Height = width, height
+	}
+
+	f.URL = i.FilePlaceholderURL
+	if err := ValidateEventFields(f); err != nil {
+		return "", fmt.Errorf("couldn't send file %q: %w", name, err)
+	}
+	f.URL, err = a.UploadFile(name, data)
+	if err != nil {
+		return "", fmt.Errorf("couldn't upload file %q: %w", name, err)
+	}
+	eventID, err := a.SendEvent(chatID, f, opts.AttachToLastThread)
+	if err != nil {
+		return "", fmt.Errorf("couldn't send file %q: %w", name, err)
+	}
+	return eventID, nil
+}
+
 // SendRichMessagePostback sends postback for given rich message event.
 func (a *API) SendRichMessagePostback(chatID, eventID, threadID, postbackID string, toggled bool) error {
 	return a.Call("send_rich_message_postback", &sendRichMessagePostbackRequest{
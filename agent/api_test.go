@@ -2,8 +2,14 @@ package agent_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
 	"io"
 	"net/http"
+	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -182,6 +188,21 @@ var mockedResponses = map[string]string{
         "type": "message",
         "text": "Hello",
         "author_id": "smith@example.com"
+      }, {
+        "id": "Q20N9CKRX2_2",
+        "created_at": "2019-12-17T07:57:42.512000Z",
+        "visibility": "all",
+        "type": "custom",
+        "content": {"order_id": "1234"},
+        "author_id": "smith@example.com"
+      }, {
+        "id": "Q20N9CKRX2_3",
+        "created_at": "2019-12-17T07:57:43.512000Z",
+        "visibility": "all",
+        "type": "form",
+        "form_id": "35c7ae4e",
+        "fields": [{"id": "name", "type": "name", "label": "Your name", "required": true}],
+        "author_id": "smith@example.com"
       }],
       "properties": {},
       "access": {
@@ -538,6 +559,57 @@ func TestSendEventShouldReturnDataReceivedFromAgentAPI(t *testing.T) {
 	}
 }
 
+func TestSendEventShouldAcceptCustomEvent(t *testing.T) {
+	client := NewTestClient(createMockedResponder(t, "send_event"))
+
+	api, err := agent.NewAPI(stubBearerTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	event := &agent.CustomEvent{
+		Event:   agent.Event{Type: "custom"},
+		Content: json.RawMessage(`{"order_id": "1234"}`),
+	}
+	if _, rErr := api.SendEvent("stubChatID", event, false); rErr != nil {
+		t.Errorf("SendEvent failed: %v", rErr)
+	}
+}
+
+func TestSendEventShouldValidateEventFieldsUnlessDisabled(t *testing.T) {
+	requests := 0
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		requests++
+		return createMockedResponder(t, "send_event")(req)
+	})
+
+	api, err := agent.NewAPI(stubBearerTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	event := &agent.Message{
+		Event: agent.Event{Type: "message", Visibility: "everyone"},
+		Text:  " ",
+	}
+	_, rErr := api.SendEvent("stubChatID", event, false)
+	var validationErr *agent.EventValidationError
+	if !errors.As(rErr, &validationErr) || len(validationErr.Fields) != 2 {
+		t.Fatalf("Invalid error: %v", rErr)
+	}
+	if requests != 0 {
+		t.Errorf("Invalid event shouldn't be sent")
+	}
+
+	api.SetEventValidation(false)
+	if _, rErr := api.SendEvent("stubChatID", event, false); rErr != nil {
+		t.Errorf("SendEvent failed: %v", rErr)
+	}
+	if requests != 1 {
+		t.Errorf("Event should be sent with validation disabled")
+	}
+}
+
 func TestResumeChatShouldReturnDataReceivedFromAgentAPI(t *testing.T) {
 	client := NewTestClient(createMockedResponder(t, "resume_chat"))
 
@@ -646,7 +718,22 @@ func TestListThreadsShouldReturnDataReceivedFromCustomerAPI(t *testing.T) {
 	}
 
 	if len(threads) != 1 {
-		t.Errorf("Received invalid threads length: %v", len(threads))
+		t.Fatalf("Received invalid threads length: %v", len(threads))
+	}
+
+	if len(threads[0].Events) != 3 {
+		t.Fatalf("Received invalid events length: %v", len(threads[0].Events))
+	}
+
+	var content struct {
+		OrderID string `json:"order_id"`
+	}
+	if custom := threads[0].Events[1].CustomEvent(); custom == nil || custom.UnmarshalContent(&content) != nil || content.OrderID != "1234" {
+		t.Errorf("Invalid custom event: %+v", threads[0].Events[1])
+	}
+
+	if form := threads[0].Events[2].Form(); form == nil || form.FormID != "35c7ae4e" || !form.Fields[0].Required {
+		t.Errorf("Invalid form event: %+v", threads[0].Events[2])
 	}
 
 	if found != 1 {
@@ -777,6 +864,95 @@ func TestRemoveUserFromChatShouldReturnDataReceivedFromAgentAPI(t *testing.T) {
 	}
 }
 
+func TestSendFileShouldUploadAndSendFileEvent(t *testing.T) {
+	var sent struct {
+		Event agent.File `json:"event"`
+	}
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		action := path.Base(req.URL.Path)
+		if action == "send_event" {
+			json.NewDecoder(req.Body).Decode(&sent)
+		}
+		return createMockedResponder(t, action)(req)
+	})
+
+	api, err := agent.NewAPI(stubBearerTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 40, 30)))
+	size := img.Len()
+	eventID, rErr := api.SendFile("stubChatID", "image.png", &img, &agent.SendFileOptions{Visibility: "all"})
+	if rErr != nil {
+		t.Fatalf("SendFile failed: %v", rErr)
+	}
+
+	if eventID != "K600PKZON8" {
+		t.Errorf("Invalid eventID: %v", eventID)
+	}
+	f := sent.Event
+	if f.Type != "file" || f.Visibility != "all" || f.Name != "image.png" || f.ContentType != "image/png" || f.Size != size {
+		t.Errorf("Invalid file event: %+v", f)
+	}
+	if f.Width != 40 || f.Height != 30 || f.URL == "" {
+		t.Errorf("Invalid image details: %+v", f)
+	}
+}
+
+func TestSendFileShouldReportFailedStage(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		if action := path.Base(req.URL.Path); action != "upload_file" {
+			t.Errorf("Unexpected request: %v", action)
+		}
+		return createMockedErrorResponder(t, "upload_file")(req)
+	})
+
+	api, err := agent.NewAPI(stubBearerTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	_, rErr := api.SendFile("stubChatID", "notes.txt", strings.NewReader("notes"), nil)
+	if rErr == nil || !strings.HasPrefix(rErr.Error(), `couldn't upload file "notes.txt"`) {
+		t.Errorf("Invalid error: %v", rErr)
+	}
+}
+
+func TestSendFileShouldNotUploadRejectedFile(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		t.Errorf("Unexpected request: %v", path.Base(req.URL.Path))
+		return createMockedResponder(t, path.Base(req.URL.Path))(req)
+	})
+
+	api, err := agent.NewAPI(stubBearerTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	_, rErr := api.SendFile("stubChatID", "notes.txt", strings.NewReader("notes"), &agent.SendFileOptions{Visibility: "everyone"})
+	var validationErr *agent.EventValidationError
+	if !errors.As(rErr, &validationErr) || validationErr.Fields[0].Field != "visibility" {
+		t.Errorf("Invalid error: %v", rErr)
+	}
+
+	_, rErr = api.SendFile("stubChatID", "large.bin", zeroReader{}, nil)
+	if rErr == nil || rErr.Error() != `couldn't read file "large.bin": file exceeds 10485760 bytes` {
+		t.Errorf("Invalid error: %v", rErr)
+	}
+}
+
+// zeroReader is an endless stream of zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
 func TestSendRichMessagePostbackShouldReturnDataReceivedFromAgentAPI(t *testing.T) {
 	client := NewTestClient(createMockedResponder(t, "send_rich_message_postback"))
 
@@ -0,0 +1,235 @@
+// Package attachments implements downloading of files sent in LiveChat chats.
+//
+// Downloader works with File events of all the packages (agent.File, customer.File and webhooks.File are the same
+// structure), so attachments can be archived no matter whether events come from the API or from webhooks.
+package attachments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/authorization"
+	"github.com/livechat/lc-sdk-go/v6/objects"
+)
+
+const (
+	// DefaultMaxSize is the default limit of downloaded file size, matching the limit of files uploaded to LiveChat.
+	DefaultMaxSize = 10 << 20
+	// DefaultConcurrency is the default number of files downloaded concurrently by DownloadAll.
+	DefaultConcurrency = 4
+)
+
+var (
+	// ErrTooLarge is returned when file exceeds maximum size of Downloader.
+	ErrTooLarge = errors.New("file exceeds maximum size")
+	// ErrSizeMismatch is returned when size of downloaded file differs from Size declared in File event.
+	ErrSizeMismatch = errors.New("downloaded file size doesn't match file event")
+	// ErrNoURL is returned when File event has no URL of requested variant.
+	ErrNoURL = errors.New("file has no URL of requested variant")
+)
+
+// Variant specifies which version of the file is downloaded.
+type Variant int
+
+// Possible values of Variant.
+const (
+	Original Variant = iota
+	Thumbnail
+	Thumbnail2x
+)
+
+func (v Variant) String() string {
+	switch v {
+	case Original:
+		return "original"
+	case Thumbnail:
+		return "thumbnail"
+	case Thumbnail2x:
+		return "thumbnail2x"
+	}
+	return fmt.Sprintf("Variant(%d)", int(v))
+}
+
+// Downloader downloads files of File events.
+type Downloader struct {
+	tokenGetter     authorization.TokenGetter
+	httpClient      *http.Client
+	maxSize         int64
+	concurrency     int
+	authorizedHosts []string
+}
+
+// NewDownloader returns ready to use Downloader, which authorizes requests with tokens from given TokenGetter
+// (usually the same one which is used by agent or customer API client). TokenGetter might be nil if files
+// should be downloaded without authorization.
+//
+// If provided client is nil, then default http client with 20s timeout is used.
+func NewDownloader(t authorization.TokenGetter, client *http.Client) *Downloader {
+	if client == nil {
+		client = &http.Client{
+			Timeout: 20 * time.Second,
+		}
+	}
+	return &Downloader{
+		tokenGetter:     t,
+		httpClient:      client,
+		maxSize:         DefaultMaxSize,
+		concurrency:     DefaultConcurrency,
+		authorizedHosts: []string{"livechat-files.com", "livechat-static.com", "livechatinc.com"},
+	}
+}
+
+// WithMaxSize allows to change maximum size of downloaded file in bytes.
+func (d *Downloader) WithMaxSize(maxSize int64) *Downloader {
+	d.maxSize = maxSize
+	return d
+}
+
+// WithConcurrency allows to change number of files downloaded concurrently by DownloadAll.
+func (d *Downloader) WithConcurrency(concurrency int) *Downloader {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	d.concurrency = concurrency
+	return d
+}
+
+// WithAuthorizedHosts allows to change hosts to which the token is sent. Token is sent only over HTTPS
+// to given hosts and their subdomains, so that it isn't leaked to hosts from URLs of crafted file events.
+//
+// By default token is sent to LiveChat domains.
+func (d *Downloader) WithAuthorizedHosts(hosts ...string) *Downloader {
+	d.authorizedHosts = hosts
+	return d
+}
+
+// Download streams given variant of the file to w. It returns number of written bytes.
+//
+// Original file is verified against Size of File event, if it's set. If the file turns out to be too large or
+// its size doesn't match, part of it might have been already written to w.
+func (d *Downloader) Download(ctx context.Context, f *objects.File, variant Variant, w io.Writer) (int64, error) {
+	fileURL, err := variantURL(f, variant)
+	if err != nil {
+		return 0, err
+	}
+	if variant == Original && int64(f.Size) > d.maxSize {
+		return 0, fmt.Errorf("couldn't download %v: %w", f.Name, ErrTooLarge)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't create new http request: %v", err)
+	}
+	if d.tokenGetter != nil && d.isAuthorizedHost(req.URL) {
+		token := d.tokenGetter()
+		if token == nil {
+			return 0, errors.New("couldn't get token")
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", token.Type, token.AccessToken))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't download %v: %w", f.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("couldn't download %v: unexpected status %v", f.Name, resp.Status)
+	}
+	if resp.ContentLength > d.maxSize {
+		return 0, fmt.Errorf("couldn't download %v: %w", f.Name, ErrTooLarge)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, d.maxSize+1))
+	if err != nil {
+		return n, fmt.Errorf("couldn't download %v: %w", f.Name, err)
+	}
+	if n > d.maxSize {
+		return n, fmt.Errorf("couldn't download %v: %w", f.Name, ErrTooLarge)
+	}
+	if variant == Original && f.Size > 0 && n != int64(f.Size) {
+		return n, fmt.Errorf("couldn't download %v: %w: got %v bytes, expected %v", f.Name, ErrSizeMismatch, n, f.Size)
+	}
+	return n, nil
+}
+
+// DownloadAll downloads given variant of files concurrently, with at most the number of concurrent downloads
+// set with WithConcurrency. Writer for each file is obtained with open function and closed after the download.
+//
+// It returns errors of particular files, at indexes matching the files; nil error means file was downloaded.
+func (d *Downloader) DownloadAll(ctx context.Context, files []*objects.File, variant Variant, open func(f *objects.File) (io.WriteCloser, error)) []error {
+	errs := make([]error, len(files))
+	sem := make(chan struct{}, d.concurrency)
+	var wg sync.WaitGroup
+	for i, f := range files {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(files); j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+		wg.Add(1)
+		go func(i int, f *objects.File) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = d.downloadTo(ctx, f, variant, open)
+		}(i, f)
+	}
+	wg.Wait()
+	return errs
+}
+
+func (d *Downloader) downloadTo(ctx context.Context, f *objects.File, variant Variant, open func(f *objects.File) (io.WriteCloser, error)) error {
+	w, err := open(f)
+	if err != nil {
+		return fmt.Errorf("couldn't open writer for %v: %v", f.Name, err)
+	}
+	_, err = d.Download(ctx, f, variant, w)
+	if closeErr := w.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("couldn't close writer for %v: %v", f.Name, closeErr)
+	}
+	return err
+}
+
+func (d *Downloader) isAuthorizedHost(u *url.URL) bool {
+	if u.Scheme != "https" {
+		return false
+	}
+	host := u.Hostname()
+	for _, h := range d.authorizedHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func variantURL(f *objects.File, variant Variant) (string, error) {
+	var fileURL string
+	switch variant {
+	case Original:
+		fileURL = f.URL
+	case Thumbnail:
+		fileURL = f.ThumbnailURL
+	case Thumbnail2x:
+		fileURL = f.Thumbnail2xURL
+	default:
+		return "", fmt.Errorf("unsupported variant: %v", variant)
+	}
+	if fileURL == "" {
+		return "", fmt.Errorf("couldn't download %v %v: %w", variant, f.Name, ErrNoURL)
+	}
+	return fileURL, nil
+}
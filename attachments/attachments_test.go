@@ -0,0 +1,170 @@
+package attachments_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/attachments"
+	"github.com/livechat/lc-sdk-go/v6/authorization"
+	"github.com/livechat/lc-sdk-go/v6/objects"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+func stubTokenGetter() *authorization.Token {
+	return &authorization.Token{AccessToken: "access_token", Region: "region", Type: authorization.BearerToken}
+}
+
+// cdn is a stand-in of LiveChat CDN serving files of given contents by path.
+type cdn struct {
+	mu            sync.Mutex
+	files         map[string]string
+	authHeaders   []string
+	active        int
+	maxActive     int
+	responseDelay time.Duration
+}
+
+func (c *cdn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.authHeaders = append(c.authHeaders, r.Header.Get("Authorization"))
+	c.active++
+	if c.active > c.maxActive {
+		c.maxActive = c.active
+	}
+	content, exists := c.files[r.URL.Path]
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.active--
+		c.mu.Unlock()
+	}()
+
+	time.Sleep(c.responseDelay)
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+	io.WriteString(w, content)
+}
+
+func newCDN(t *testing.T, files map[string]string) (*cdn, *httptest.Server) {
+	c := &cdn{files: files}
+	srv := httptest.NewTLSServer(c)
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+func TestDownloadVariants(t *testing.T) {
+	c, srv := newCDN(t, map[string]string{"/image.png": "image", "/thumb.png": "thumb"})
+	d := attachments.NewDownloader(stubTokenGetter, srv.Client()).WithAuthorizedHosts("127.0.0.1")
+
+	f := &webhooks.File{Name: "image.png", URL: srv.URL + "/image.png", ThumbnailURL: srv.URL + "/thumb.png", Size: 5}
+	var buf bytes.Buffer
+	if n, err := d.Download(context.Background(), f, attachments.Original, &buf); err != nil || n != 5 || buf.String() != "image" {
+		t.Errorf("invalid download: %v, %v, %q", n, err, buf.String())
+	}
+	buf.Reset()
+	if _, err := d.Download(context.Background(), f, attachments.Thumbnail, &buf); err != nil || buf.String() != "thumb" {
+		t.Errorf("invalid thumbnail download: %v, %q", err, buf.String())
+	}
+	if _, err := d.Download(context.Background(), f, attachments.Thumbnail2x, &buf); !errors.Is(err, attachments.ErrNoURL) {
+		t.Errorf("missing thumbnail should be reported: %v", err)
+	}
+	if c.authHeaders[0] != "Bearer access_token" {
+		t.Errorf("invalid Authorization header: %v", c.authHeaders[0])
+	}
+}
+
+func TestDownloadVerifiesSize(t *testing.T) {
+	_, srv := newCDN(t, map[string]string{"/file.txt": "contents"})
+	d := attachments.NewDownloader(stubTokenGetter, srv.Client()).WithMaxSize(5)
+
+	f := &objects.File{Name: "file.txt", URL: srv.URL + "/file.txt"}
+	if _, err := d.Download(context.Background(), f, attachments.Original, io.Discard); !errors.Is(err, attachments.ErrTooLarge) {
+		t.Errorf("too large file should be reported: %v", err)
+	}
+
+	f.Size = 3
+	d.WithMaxSize(100)
+	if _, err := d.Download(context.Background(), f, attachments.Original, io.Discard); !errors.Is(err, attachments.ErrSizeMismatch) {
+		t.Errorf("size mismatch should be reported: %v", err)
+	}
+
+	f.Size = 200
+	if _, err := d.Download(context.Background(), f, attachments.Original, io.Discard); !errors.Is(err, attachments.ErrTooLarge) {
+		t.Errorf("file declared as too large should be reported: %v", err)
+	}
+}
+
+func TestDownloadDoesntSendTokenToUnauthorizedHosts(t *testing.T) {
+	c, srv := newCDN(t, map[string]string{"/file.txt": "contents"})
+	d := attachments.NewDownloader(stubTokenGetter, srv.Client())
+
+	f := &objects.File{Name: "file.txt", URL: srv.URL + "/file.txt"}
+	if _, err := d.Download(context.Background(), f, attachments.Original, io.Discard); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if c.authHeaders[0] != "" {
+		t.Errorf("token shouldn't be sent: %v", c.authHeaders[0])
+	}
+}
+
+type buffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *buffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDownloadAllBoundsConcurrency(t *testing.T) {
+	files := make(map[string]string)
+	var events []*objects.File
+	for i := 0; i < 10; i++ {
+		path := fmt.Sprintf("/file_%v.txt", i)
+		files[path] = strings.Repeat("a", i+1)
+		events = append(events, &objects.File{Name: path, URL: "PLACEHOLDER" + path, Size: i + 1})
+	}
+	c, srv := newCDN(t, files)
+	c.responseDelay = 10 * time.Millisecond
+	for _, e := range events {
+		e.URL = strings.Replace(e.URL, "PLACEHOLDER", srv.URL, 1)
+	}
+	events = append(events, &objects.File{Name: "missing.txt", URL: srv.URL + "/missing.txt"})
+
+	var mu sync.Mutex
+	buffers := make(map[string]*buffer)
+	d := attachments.NewDownloader(stubTokenGetter, srv.Client()).WithConcurrency(3)
+	errs := d.DownloadAll(context.Background(), events, attachments.Original, func(f *objects.File) (io.WriteCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		buffers[f.Name] = &buffer{}
+		return buffers[f.Name], nil
+	})
+
+	for i, err := range errs[:10] {
+		if err != nil {
+			t.Errorf("download of %v failed: %v", events[i].Name, err)
+		}
+		if b := buffers[events[i].Name]; b.Len() != i+1 || !b.closed {
+			t.Errorf("invalid download of %v: %v bytes, closed: %v", events[i].Name, b.Len(), b.closed)
+		}
+	}
+	if errs[10] == nil {
+		t.Error("missing file should be reported")
+	}
+	if c.maxActive > 3 {
+		t.Errorf("too many concurrent downloads: %v", c.maxActive)
+	}
+}
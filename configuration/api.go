@@ -236,7 +236,7 @@ func (a *API) UnregisterProperty(name, ownerClientID string) error {
 
 // PublishProperty publishes private property
 func (a *API) PublishProperty(name, ownerClientID string, read, write bool) error {
-	accessType := make([]string, 2)
+	accessType := make([]string, 0, 2)
 	if read {
 		accessType = append(accessType, "read")
 	}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/livechat/lc-sdk-go/v6/authorization"
@@ -711,6 +712,30 @@ func TestPublishPropertyShouldReturnDataReceivedFromConfApi(t *testing.T) {
 	}
 }
 
+func TestPublishPropertyShouldSendAccessTypes(t *testing.T) {
+	server := &routingServerMock{}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	for _, access := range [][2]bool{{true, true}, {false, true}, {true, false}} {
+		if rErr := api.PublishProperty("dummy_property", "dummy_client_id", access[0], access[1]); rErr != nil {
+			t.Errorf("PublishProperty failed: %v", rErr)
+		}
+	}
+	expected := []string{`"access_type":["read","write"]`, `"access_type":["write"]`, `"access_type":["read"]`}
+	calls := server.called("publish_property")
+	if len(calls) != len(expected) {
+		t.Fatalf("invalid publish_property calls: %v", calls)
+	}
+	for i, call := range calls {
+		if !strings.Contains(call, expected[i]) {
+			t.Errorf("invalid request body, expected %v: %v", expected[i], call)
+		}
+	}
+}
+
 func TestListPropertiesShouldReturnDataReceivedFromConfApi(t *testing.T) {
 	client := NewTestClient(newServerMock(t, "list_properties"))
 
@@ -0,0 +1,323 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrPropertyInUse is returned by ReconcileProperties in safe mode, if the plan removes properties which are in use.
+var ErrPropertyInUse = errors.New("property is in use")
+
+// PropertyChangeType describes change of registered properties performed by ReconcileProperties.
+type PropertyChangeType string
+
+// Possible values of PropertyChangeType.
+const (
+	PropertyRegister   PropertyChangeType = "register"
+	PropertyUnregister PropertyChangeType = "unregister"
+	// PropertyUpdate is performed by unregistering the property and registering it again.
+	PropertyUpdate PropertyChangeType = "update"
+	// PropertyPublish extends only public access of the property.
+	PropertyPublish PropertyChangeType = "publish"
+)
+
+// PropertyChange represents single change of registered properties.
+type PropertyChange struct {
+	Type PropertyChangeType
+	Name string
+	// Property is the desired property configuration. It's nil for PropertyUnregister.
+	Property *PropertyConfig
+	// Registered is the current property configuration. It's nil for PropertyRegister.
+	Registered *PropertyConfig
+}
+
+func (c PropertyChange) String() string {
+	switch c.Type {
+	case PropertyRegister:
+		return fmt.Sprintf("register %v (%v)%v", c.Name, c.Property.Type, publicAccessSuffix(c.Property))
+	case PropertyUnregister:
+		return fmt.Sprintf("unregister %v (%v)", c.Name, c.Registered.Type)
+	case PropertyPublish:
+		return fmt.Sprintf("publish %v [%v]", c.Name, strings.Join(c.Property.PublicAccess, ","))
+	default:
+		return fmt.Sprintf("update %v (%v)%v", c.Name, c.Property.Type, publicAccessSuffix(c.Property))
+	}
+}
+
+func publicAccessSuffix(p *PropertyConfig) string {
+	if len(p.PublicAccess) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", publish [%v]", strings.Join(p.PublicAccess, ","))
+}
+
+// PropertiesPlan describes changes needed to reach the desired state of properties.
+type PropertiesPlan struct {
+	Changes []PropertyChange
+	// InUse lists names of properties which are unregistered or updated by the plan while being in use.
+	// It's filled only in safe mode.
+	InUse []string
+}
+
+// IsEmpty reports whether registered properties are already in the desired state.
+func (p *PropertiesPlan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns human readable description of the plan, one change per line.
+func (p *PropertiesPlan) String() string {
+	if p.IsEmpty() {
+		return "no changes\n"
+	}
+	inUse := make(map[string]bool)
+	for _, name := range p.InUse {
+		inUse[name] = true
+	}
+	var sb strings.Builder
+	for _, c := range p.Changes {
+		sb.WriteString(c.String())
+		if inUse[c.Name] && c.Type != PropertyRegister && c.Type != PropertyPublish {
+			sb.WriteString(" (in use)")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ReconcilePropertiesOptions are options for ReconcileProperties.
+type ReconcilePropertiesOptions struct {
+	// OwnerClientID is the owner of properties, used as their namespace.
+	OwnerClientID string
+	// DryRun makes ReconcileProperties only compute the plan, without applying it.
+	DryRun bool
+	// Safe makes ReconcileProperties refuse to apply the plan if it unregisters or updates properties in use.
+	Safe bool
+	// InUse reports whether property with given name is in use. By default, property is in use
+	// if it has value set within the license. Values of properties in other locations (e.g. chat, thread
+	// or event) can't be checked, so such properties are considered in use unless InUse is given.
+	InUse func(name string) (bool, error)
+}
+
+// ReconcileProperties makes properties registered for the owner match given desired configurations.
+//
+// Properties are identified by their name. Registered properties that are not desired are unregistered.
+// Registered properties with different configuration are updated, and those lacking only some of desired
+// public access are published again. As there's no way to unpublish a property, properties whose public access
+// has to be narrowed are updated.
+//
+// The returned plan describes the changes, which were applied unless DryRun is set. In safe mode, the plan isn't
+// applied if it unregisters or updates properties in use, and error wrapping ErrPropertyInUse is returned.
+// If applying the plan fails, the plan is returned along with the error; changes preceding the failed one
+// remain applied.
+func (a *API) ReconcileProperties(desired []*PropertyConfig, opts *ReconcilePropertiesOptions) (*PropertiesPlan, error) {
+	if opts == nil {
+		opts = &ReconcilePropertiesOptions{}
+	}
+	registered, err := a.ListProperties(opts.OwnerClientID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list properties: %v", err)
+	}
+	plan, err := planProperties(desired, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Safe {
+		inUse := opts.InUse
+		if inUse == nil {
+			inUse = a.licensePropertyInUse(opts.OwnerClientID)
+		}
+		for _, c := range plan.Changes {
+			if c.Type != PropertyUnregister && c.Type != PropertyUpdate {
+				continue
+			}
+			if opts.InUse == nil && !licenseOnlyProperty(c.Registered) {
+				plan.InUse = append(plan.InUse, c.Name)
+				continue
+			}
+			used, err := inUse(c.Name)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't check if property %v is in use: %v", c.Name, err)
+			}
+			if used {
+				plan.InUse = append(plan.InUse, c.Name)
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+	if len(plan.InUse) > 0 {
+		return plan, fmt.Errorf("couldn't apply properties plan: %w: %v", ErrPropertyInUse, strings.Join(plan.InUse, ", "))
+	}
+	for _, c := range plan.Changes {
+		if err := a.applyPropertyChange(c, opts.OwnerClientID); err != nil {
+			return plan, fmt.Errorf("couldn't %v: %v", c, err)
+		}
+	}
+	return plan, nil
+}
+
+func (a *API) applyPropertyChange(c PropertyChange, ownerClientID string) error {
+	if c.Type == PropertyUnregister || c.Type == PropertyUpdate {
+		if err := a.UnregisterProperty(c.Name, ownerClientID); err != nil {
+			return err
+		}
+	}
+	if c.Type == PropertyRegister || c.Type == PropertyUpdate {
+		p := *c.Property
+		p.OwnerClientID = ownerClientID
+		p.PublicAccess = nil
+		if err := a.RegisterProperty(&p); err != nil {
+			return err
+		}
+	}
+	if c.Type == PropertyPublish || c.Property != nil && len(c.Property.PublicAccess) > 0 {
+		read, write := publicAccess(c.Property.PublicAccess)
+		if err := a.PublishProperty(c.Name, ownerClientID, read, write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// licensePropertyInUse returns function checking if property has value set within the license.
+// License properties are fetched once, on first call.
+func (a *API) licensePropertyInUse(ownerClientID string) func(name string) (bool, error) {
+	var props Properties
+	return func(name string) (bool, error) {
+		if props == nil {
+			var err error
+			props, err = a.ListLicenseProperties(&ListLicensePropertiesRequestOptions{Namespace: ownerClientID})
+			if err != nil {
+				return false, err
+			}
+			if props == nil {
+				props = Properties{}
+			}
+		}
+		for namespace, values := range props {
+			if ownerClientID != "" && namespace != ownerClientID {
+				continue
+			}
+			if _, exists := values[name]; exists {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// licenseOnlyProperty reports whether property is available only in license location, so that
+// licensePropertyInUse can check if it's in use.
+func licenseOnlyProperty(p *PropertyConfig) bool {
+	for location, access := range p.Access {
+		if location != "license" && access != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func planProperties(desired []*PropertyConfig, registered map[string]*PropertyConfig) (*PropertiesPlan, error) {
+	plan := &PropertiesPlan{}
+	seen := make(map[string]bool)
+	for _, p := range desired {
+		if seen[p.Name] {
+			return nil, fmt.Errorf("property %v is defined more than once", p.Name)
+		}
+		seen[p.Name] = true
+
+		rp, exists := registered[p.Name]
+		if !exists || rp == nil {
+			plan.Changes = append(plan.Changes, PropertyChange{Type: PropertyRegister, Name: p.Name, Property: p})
+			continue
+		}
+		same, err := samePropertyDefinition(p, rp)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't compare property %v: %v", p.Name, err)
+		}
+		switch {
+		// Public access can't be revoked, so the property is registered again to narrow it.
+		case !same, !containsStrings(p.PublicAccess, rp.PublicAccess):
+			plan.Changes = append(plan.Changes, PropertyChange{Type: PropertyUpdate, Name: p.Name, Property: p, Registered: rp})
+		case !sameStrings(p.PublicAccess, rp.PublicAccess):
+			plan.Changes = append(plan.Changes, PropertyChange{Type: PropertyPublish, Name: p.Name, Property: p, Registered: rp})
+		}
+	}
+
+	var unregister []string
+	for name := range registered {
+		if !seen[name] {
+			unregister = append(unregister, name)
+		}
+	}
+	sort.Strings(unregister)
+	for _, name := range unregister {
+		plan.Changes = append(plan.Changes, PropertyChange{Type: PropertyUnregister, Name: name, Registered: registered[name]})
+	}
+	return plan, nil
+}
+
+// samePropertyDefinition compares configurations of properties, ignoring their name, owner and public access
+// (ListProperties doesn't return names within configurations).
+// Configurations are compared in JSON form, so that e.g. domain values decoded as float64 match desired ints.
+func samePropertyDefinition(a, b *PropertyConfig) (bool, error) {
+	ja, err := propertyDefinitionJSON(a)
+	if err != nil {
+		return false, err
+	}
+	jb, err := propertyDefinitionJSON(b)
+	if err != nil {
+		return false, err
+	}
+	return ja == jb, nil
+}
+
+func propertyDefinitionJSON(p *PropertyConfig) (string, error) {
+	c := *p
+	c.Name = ""
+	c.OwnerClientID = ""
+	c.PublicAccess = nil
+	c.Access = make(map[string]*PropertyAccess, len(p.Access))
+	for location, access := range p.Access {
+		if access == nil {
+			continue
+		}
+		normalized := &PropertyAccess{
+			Agent:    append([]string(nil), access.Agent...),
+			Customer: append([]string(nil), access.Customer...),
+		}
+		sort.Strings(normalized.Agent)
+		sort.Strings(normalized.Customer)
+		c.Access[location] = normalized
+	}
+	data, err := json.Marshal(&c)
+	return string(data), err
+}
+
+// containsStrings reports whether all values of sub are in values.
+func containsStrings(values, sub []string) bool {
+	for _, s := range sub {
+		if !containsString(values, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func publicAccess(accessTypes []string) (read, write bool) {
+	for _, t := range accessTypes {
+		switch t {
+		case "read":
+			read = true
+		case "write":
+			write = true
+		}
+	}
+	return read, write
+}
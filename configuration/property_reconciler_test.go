@@ -0,0 +1,222 @@
+package configuration_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+)
+
+const registeredProperties = `{
+	"score": {
+		"type": "int",
+		"access": {"chat": {"agent": ["write", "read"], "customer": ["read"]}},
+		"domain": [1, 2, 3]
+	},
+	"comment": {
+		"type": "string",
+		"access": {"chat": {"agent": ["read"]}}
+	},
+	"legacy": {
+		"type": "bool",
+		"access": {"chat": {"agent": ["read"]}}
+	},
+	"segment": {
+		"type": "string",
+		"access": {"chat": {"agent": ["read"]}},
+		"public_access": ["read"]
+	}
+}`
+
+var desiredProperties = []*configuration.PropertyConfig{
+	{
+		Name:   "score",
+		Type:   "int",
+		Access: map[string]*configuration.PropertyAccess{"chat": {Agent: []string{"read", "write"}, Customer: []string{"read"}}},
+		Domain: []interface{}{1, 2, 3},
+	},
+	{
+		Name:   "comment",
+		Type:   "string",
+		Access: map[string]*configuration.PropertyAccess{"chat": {Agent: []string{"read", "write"}}},
+	},
+	{
+		Name:         "segment",
+		Type:         "string",
+		Access:       map[string]*configuration.PropertyAccess{"chat": {Agent: []string{"read"}}},
+		PublicAccess: []string{"read", "write"},
+	},
+	{
+		Name:         "channel",
+		Type:         "string",
+		Access:       map[string]*configuration.PropertyAccess{"chat": {Agent: []string{"read"}}},
+		PublicAccess: []string{"read"},
+	},
+}
+
+func TestReconcilePropertiesDryRun(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_properties": registeredProperties,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	plan, err := api.ReconcileProperties(desiredProperties, &configuration.ReconcilePropertiesOptions{OwnerClientID: "owner", DryRun: true})
+	if err != nil {
+		t.Fatalf("ReconcileProperties failed: %v", err)
+	}
+	expected := "update comment (string)\n" +
+		"publish segment [read,write]\n" +
+		"register channel (string), publish [read]\n" +
+		"unregister legacy (bool)\n"
+	if plan.String() != expected {
+		t.Errorf("invalid plan:\n%v", plan)
+	}
+	for _, method := range []string{"register_property", "unregister_property", "publish_property", "list_license_properties"} {
+		if calls := server.called(method); len(calls) != 0 {
+			t.Errorf("%v shouldn't be called in dry run", method)
+		}
+	}
+}
+
+func TestReconcilePropertiesAppliesPlan(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_properties": registeredProperties,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	if _, err := api.ReconcileProperties(desiredProperties, &configuration.ReconcilePropertiesOptions{OwnerClientID: "owner"}); err != nil {
+		t.Fatalf("ReconcileProperties failed: %v", err)
+	}
+	unregistered := server.called("unregister_property")
+	if len(unregistered) != 2 || !strings.Contains(unregistered[0], `"comment"`) || !strings.Contains(unregistered[1], `"legacy"`) {
+		t.Errorf("invalid unregistered properties: %v", unregistered)
+	}
+	registered := server.called("register_property")
+	if len(registered) != 2 || !strings.Contains(registered[0], `"comment"`) || !strings.Contains(registered[1], `"channel"`) {
+		t.Errorf("invalid registered properties: %v", registered)
+	}
+	if strings.Contains(registered[1], "public_access") || !strings.Contains(registered[1], `"owner_client_id":"owner"`) {
+		t.Errorf("invalid register request: %v", registered[1])
+	}
+	published := server.called("publish_property")
+	if len(published) != 2 || !strings.Contains(published[0], `"access_type":["read","write"]`) || !strings.Contains(published[1], `"access_type":["read"]`) {
+		t.Errorf("invalid published properties: %v", published)
+	}
+}
+
+func TestReconcilePropertiesSafeModeRefusesToRemovePropertiesInUse(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_properties": registeredProperties,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	inUse := func(name string) (bool, error) {
+		return name == "legacy", nil
+	}
+	plan, err := api.ReconcileProperties(desiredProperties, &configuration.ReconcilePropertiesOptions{OwnerClientID: "owner", Safe: true, InUse: inUse})
+	if !errors.Is(err, configuration.ErrPropertyInUse) {
+		t.Fatalf("expected ErrPropertyInUse, got %v", err)
+	}
+	if len(plan.InUse) != 1 || plan.InUse[0] != "legacy" {
+		t.Errorf("invalid properties in use: %v", plan.InUse)
+	}
+	if !strings.Contains(plan.String(), "unregister legacy (bool) (in use)\n") {
+		t.Errorf("invalid plan:\n%v", plan)
+	}
+	for _, method := range []string{"register_property", "unregister_property", "publish_property"} {
+		if calls := server.called(method); len(calls) != 0 {
+			t.Errorf("%v shouldn't be called in safe mode", method)
+		}
+	}
+}
+
+func TestReconcilePropertiesSafeModeChecksLicenseProperties(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_properties": `{
+			"plan": {"type": "string", "access": {"license": {"agent": ["read"]}}},
+			"region": {"type": "string", "access": {"license": {"agent": ["read"]}}},
+			"score": {"type": "int", "access": {"chat": {"agent": ["read"]}}}
+		}`,
+		"list_license_properties": `{"owner": {"plan": "pro"}}`,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	plan, err := api.ReconcileProperties(nil, &configuration.ReconcilePropertiesOptions{OwnerClientID: "owner", Safe: true})
+	if !errors.Is(err, configuration.ErrPropertyInUse) {
+		t.Fatalf("expected ErrPropertyInUse, got %v", err)
+	}
+	// Values of chat properties can't be checked, so they're considered in use by default.
+	if strings.Join(plan.InUse, ",") != "plan,score" {
+		t.Errorf("invalid properties in use: %v", plan.InUse)
+	}
+	if calls := server.called("list_license_properties"); len(calls) != 1 {
+		t.Errorf("license properties should be listed once, got %v calls", len(calls))
+	}
+}
+
+func TestReconcilePropertiesUpdatesPropertyToNarrowPublicAccess(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_properties": registeredProperties,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	desired := []*configuration.PropertyConfig{{
+		Name:   "segment",
+		Type:   "string",
+		Access: map[string]*configuration.PropertyAccess{"chat": {Agent: []string{"read"}}},
+	}}
+	plan, err := api.ReconcileProperties(desired, &configuration.ReconcilePropertiesOptions{OwnerClientID: "owner"})
+	if err != nil {
+		t.Fatalf("ReconcileProperties failed: %v", err)
+	}
+	if !strings.HasPrefix(plan.String(), "update segment (string)\n") {
+		t.Errorf("invalid plan:\n%v", plan)
+	}
+	if calls := server.called("register_property"); len(calls) != 1 || !strings.Contains(calls[0], `"segment"`) {
+		t.Errorf("invalid registered properties: %v", calls)
+	}
+	if calls := server.called("publish_property"); len(calls) != 0 {
+		t.Errorf("property shouldn't be published: %v", calls)
+	}
+}
+
+func TestReconcilePropertiesInDesiredState(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_properties": `{"score": {"type": "int", "access": {"chat": {"agent": ["read"]}}, "range": {"from": 1, "to": 5}, "default_value": 3}}`,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	desired := []*configuration.PropertyConfig{{Name: "score", Type: "int", DefaultValue: 3}}
+	desired[0].Access = map[string]*configuration.PropertyAccess{"chat": {Agent: []string{"read"}}}
+	desired[0].Range = &struct {
+		From int `json:"from"`
+		To   int `json:"to"`
+	}{From: 1, To: 5}
+	plan, err := api.ReconcileProperties(desired, &configuration.ReconcilePropertiesOptions{Safe: true})
+	if err != nil {
+		t.Fatalf("ReconcileProperties failed: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("plan should be empty:\n%v", plan)
+	}
+}
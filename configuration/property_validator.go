@@ -0,0 +1,187 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Actors whose access rights are checked by PropertyValidator, see PropertyAccess.
+const (
+	PropertyActorAgent    = "agent"
+	PropertyActorCustomer = "customer"
+)
+
+// Locations of properties, used as keys of PropertyConfig.Access.
+const (
+	PropertyLocationChat   = "chat"
+	PropertyLocationThread = "thread"
+	PropertyLocationEvent  = "event"
+	PropertyLocationGroup  = "group"
+)
+
+// PropertyConfigs represents configurations of properties in form of property_namespace -> property -> configuration.
+type PropertyConfigs map[string]map[string]*PropertyConfig
+
+// PropertyValueError is returned by PropertyValidator when value of property is invalid.
+type PropertyValueError struct {
+	Namespace string
+	Name      string
+	Reason    string
+}
+
+func (e *PropertyValueError) Error() string {
+	return fmt.Sprintf("invalid property %v.%v: %v", e.Namespace, e.Name, e.Reason)
+}
+
+// LoadPropertyConfigs returns configurations of properties registered by given owners. Namespace of properties
+// is their owner's client ID.
+//
+// Returned configurations can be stored and reused in NewPropertyValidator, so that they're not loaded each time.
+func (a *API) LoadPropertyConfigs(ownerClientIDs ...string) (PropertyConfigs, error) {
+	if len(ownerClientIDs) == 0 {
+		return nil, errors.New("couldn't load property configs: no owner client IDs")
+	}
+	configs := make(PropertyConfigs, len(ownerClientIDs))
+	for _, ownerClientID := range ownerClientIDs {
+		props, err := a.ListProperties(ownerClientID)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list properties of %v: %v", ownerClientID, err)
+		}
+		configs[ownerClientID] = props
+	}
+	return configs, nil
+}
+
+// PropertyValidator checks values of properties against their configurations before they're sent to the API.
+type PropertyValidator struct {
+	configs PropertyConfigs
+}
+
+// NewPropertyValidator creates PropertyValidator for given configurations, loaded with LoadPropertyConfigs
+// or from a cache.
+func NewPropertyValidator(configs PropertyConfigs) *PropertyValidator {
+	return &PropertyValidator{configs: configs}
+}
+
+// Validate checks if given actor (PropertyActorAgent or PropertyActorCustomer) can set given properties
+// in given location, e.g. PropertyLocationChat for properties updated with UpdateChatProperties.
+//
+// Properties are checked in order of namespaces and names. Validate returns *PropertyValueError describing
+// first invalid property.
+func (v *PropertyValidator) Validate(location, actor string, props Properties) error {
+	if actor != PropertyActorAgent && actor != PropertyActorCustomer {
+		return fmt.Errorf("unsupported property actor %q", actor)
+	}
+	namespaces := make([]string, 0, len(props))
+	for namespace := range props {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		names := make([]string, 0, len(props[namespace]))
+		for name := range props[namespace] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := v.ValidateValue(location, actor, namespace, name, props[namespace][name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateValue checks if given actor can set value of single property in given location, see Validate.
+func (v *PropertyValidator) ValidateValue(location, actor, namespace, name string, value interface{}) error {
+	fail := func(reason string, args ...interface{}) error {
+		return &PropertyValueError{Namespace: namespace, Name: name, Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	config := v.configs[namespace][name]
+	if config == nil {
+		return fail("not registered")
+	}
+	access := config.Access[location]
+	if access == nil {
+		return fail("not available in %v location", location)
+	}
+	rights := access.Agent
+	if actor == PropertyActorCustomer {
+		rights = access.Customer
+	}
+	if !containsString(rights, "write") {
+		return fail("%v can't write it in %v location", actor, location)
+	}
+
+	switch config.Type {
+	case "string", "tokenized_string":
+		if _, ok := value.(string); !ok {
+			return fail("expected %v, got %T", config.Type, value)
+		}
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return fail("expected bool, got %T", value)
+		}
+	case "int":
+		n, ok := propertyNumber(value)
+		if !ok || n != math.Trunc(n) {
+			return fail("expected int, got %v (%T)", value, value)
+		}
+		if config.Range != nil && (n < float64(config.Range.From) || n > float64(config.Range.To)) {
+			return fail("%v is out of range [%v, %v]", value, config.Range.From, config.Range.To)
+		}
+	default:
+		return fail("unsupported type %q", config.Type)
+	}
+
+	if len(config.Domain) > 0 {
+		for _, allowed := range config.Domain {
+			if samePropertyValue(value, allowed) {
+				return nil
+			}
+		}
+		return fail("%v is not in domain %v", value, config.Domain)
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// propertyNumber returns numeric value, e.g. int or float64 decoded from JSON, as float64.
+func propertyNumber(value interface{}) (float64, bool) {
+	if num, ok := value.(json.Number); ok {
+		f, err := num.Float64()
+		return f, err == nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func samePropertyValue(a, b interface{}) bool {
+	na, aIsNumber := propertyNumber(a)
+	nb, bIsNumber := propertyNumber(b)
+	if aIsNumber || bIsNumber {
+		return aIsNumber && bIsNumber && na == nb
+	}
+	return a == b
+}
@@ -0,0 +1,100 @@
+package configuration_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/agent"
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+)
+
+const validatedProperties = `{
+	"score": {
+		"type": "int",
+		"access": {"chat": {"agent": ["read", "write"], "customer": ["read", "write"]}},
+		"range": {"from": 1, "to": 5}
+	},
+	"source": {
+		"type": "string",
+		"access": {"chat": {"agent": ["read", "write"], "customer": ["read"]}},
+		"domain": ["email", "chat"]
+	},
+	"escalated": {
+		"type": "bool",
+		"access": {"thread": {"agent": ["read", "write"]}}
+	}
+}`
+
+func newPropertyValidator(t *testing.T) *configuration.PropertyValidator {
+	server := &routingServerMock{responses: map[string]string{
+		"list_properties": validatedProperties,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+	configs, err := api.LoadPropertyConfigs("owner")
+	if err != nil {
+		t.Fatalf("LoadPropertyConfigs failed: %v", err)
+	}
+	if calls := server.called("list_properties"); len(calls) != 1 || !strings.Contains(calls[0], `"owner"`) {
+		t.Errorf("invalid list_properties calls: %v", calls)
+	}
+	return configuration.NewPropertyValidator(configs)
+}
+
+func TestPropertyValidatorAcceptsValidProperties(t *testing.T) {
+	v := newPropertyValidator(t)
+	props := agent.Properties{"owner": {"score": 5, "source": "email"}}
+	if err := v.Validate(configuration.PropertyLocationChat, configuration.PropertyActorAgent, props); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+	props = agent.Properties{"owner": {"escalated": true}}
+	if err := v.Validate(configuration.PropertyLocationThread, configuration.PropertyActorAgent, props); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+	if err := v.ValidateValue(configuration.PropertyLocationChat, configuration.PropertyActorCustomer, "owner", "score", float64(1)); err != nil {
+		t.Errorf("ValidateValue failed: %v", err)
+	}
+}
+
+func TestPropertyValidatorRejectsInvalidProperties(t *testing.T) {
+	v := newPropertyValidator(t)
+	for name, tc := range map[string]struct {
+		location string
+		actor    string
+		value    interface{}
+		property string
+		expected string
+	}{
+		"unregistered": {configuration.PropertyLocationChat, configuration.PropertyActorAgent, 1, "unknown", "invalid property owner.unknown: not registered"},
+		"location":     {configuration.PropertyLocationEvent, configuration.PropertyActorAgent, 1, "score", "invalid property owner.score: not available in event location"},
+		"access":       {configuration.PropertyLocationChat, configuration.PropertyActorCustomer, "chat", "source", "invalid property owner.source: customer can't write it in chat location"},
+		"type":         {configuration.PropertyLocationChat, configuration.PropertyActorAgent, "5", "score", "invalid property owner.score: expected int, got 5 (string)"},
+		"fraction":     {configuration.PropertyLocationChat, configuration.PropertyActorAgent, 2.5, "score", "invalid property owner.score: expected int, got 2.5 (float64)"},
+		"range":        {configuration.PropertyLocationChat, configuration.PropertyActorAgent, 6, "score", "invalid property owner.score: 6 is out of range [1, 5]"},
+		"domain":       {configuration.PropertyLocationChat, configuration.PropertyActorAgent, "phone", "source", "invalid property owner.source: phone is not in domain [email chat]"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := v.Validate(tc.location, tc.actor, configuration.Properties{"owner": {tc.property: tc.value}})
+			var valueErr *configuration.PropertyValueError
+			if !errors.As(err, &valueErr) || valueErr.Name != tc.property {
+				t.Fatalf("expected PropertyValueError for %v, got %v", tc.property, err)
+			}
+			if err.Error() != tc.expected {
+				t.Errorf("invalid error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPropertyValidatorFailsOnFirstInvalidProperty(t *testing.T) {
+	v := configuration.NewPropertyValidator(configuration.PropertyConfigs{})
+	props := configuration.Properties{"b": {"x": 1}, "a": {"z": 1, "y": 1}}
+	err := v.Validate(configuration.PropertyLocationChat, configuration.PropertyActorAgent, props)
+	if err == nil || err.Error() != "invalid property a.y: not registered" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
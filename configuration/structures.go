@@ -1,7 +1,14 @@
 package configuration
 
+import "github.com/livechat/lc-sdk-go/v6/objects"
+
 // Properties represents LiveChat properties in form of property_namespace -> property -> value.
-type Properties map[string]map[string]interface{}
+//
+// It's an alias of objects.Properties, the same type as agent.Properties and customer.Properties, so that
+// properties of e.g. agent.Chat can be validated with PropertyValidator. It used to be a distinct type
+// with the same definition; code using it as before still compiles, unless it tells it apart from
+// objects.Properties, e.g. in type switches.
+type Properties = objects.Properties
 
 type ListGroupsPropertiesRequestOptions struct {
 	Namespace  string `json:"namespace,omitempty"`
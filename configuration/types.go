@@ -43,6 +43,54 @@ const (
 	BotDeleted                   WebhookAction = "bot_deleted"
 )
 
+// WebhookActions returns all supported Webhook actions.
+func WebhookActions() []WebhookAction {
+	return []WebhookAction{
+		IncomingChat,
+		IncomingEvent,
+		EventUpdated,
+		IncomingRichMessagePostback,
+		ChatDeactivated,
+		ChatPropertiesUpdated,
+		ThreadPropertiesUpdated,
+		ChatPropertiesDeleted,
+		ThreadPropertiesDeleted,
+		UserAddedToChat,
+		UserRemovedFromChat,
+		ThreadTagged,
+		ThreadUntagged,
+		AgentCreated,
+		AgentUpdated,
+		AgentDeleted,
+		AgentSuspended,
+		AgentUnsuspended,
+		AgentApproved,
+		EventsMarkedAsSeen,
+		ChatAccessUpdated,
+		EventPropertiesUpdated,
+		EventPropertiesDeleted,
+		RoutingStatusSet,
+		ChatTransferred,
+		IncomingCustomer,
+		CustomerSessionFieldsUpdated,
+		GroupCreated,
+		GroupUpdated,
+		GroupDeleted,
+		AutoAccessAdded,
+		AutoAccessUpdated,
+		AutoAccessDeleted,
+		BotCreated,
+		BotUpdated,
+		BotDeleted,
+	}
+}
+
+// Following values of Webhook's AdditionalData are supported
+const (
+	ChatPropertiesData      = "chat_properties"
+	ChatPresenceUserIDsData = "chat_presence_user_ids"
+)
+
 // GroupPriority represents priority of assigning chats in group
 type GroupPriority string
 
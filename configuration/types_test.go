@@ -0,0 +1,60 @@
+package configuration_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+)
+
+// webhookActionConstants returns values of all WebhookAction constants declared in types.go.
+func webhookActionConstants(t *testing.T) map[configuration.WebhookAction]string {
+	f, err := parser.ParseFile(token.NewFileSet(), "types.go", nil, 0)
+	if err != nil {
+		t.Fatalf("couldn't parse types.go: %v", err)
+	}
+	constants := make(map[configuration.WebhookAction]string)
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if ident, ok := vs.Type.(*ast.Ident); !ok || ident.Name != "WebhookAction" {
+				continue
+			}
+			for i, name := range vs.Names {
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					t.Fatalf("WebhookAction constant %v isn't a string literal", name.Name)
+				}
+				value, _ := strconv.Unquote(lit.Value)
+				constants[configuration.WebhookAction(value)] = name.Name
+			}
+		}
+	}
+	return constants
+}
+
+func TestWebhookActionsListsEveryConstant(t *testing.T) {
+	constants := webhookActionConstants(t)
+	listed := make(map[configuration.WebhookAction]bool)
+	for _, action := range configuration.WebhookActions() {
+		if listed[action] {
+			t.Errorf("action %v listed more than once", action)
+		}
+		listed[action] = true
+		if _, exists := constants[action]; !exists {
+			t.Errorf("action %v isn't declared as WebhookAction constant", action)
+		}
+	}
+	for action, name := range constants {
+		if !listed[action] {
+			t.Errorf("constant %v missing in WebhookActions", name)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WebhookChangeType describes change of registered webhooks performed by ReconcileWebhooks.
+type WebhookChangeType string
+
+// Possible values of WebhookChangeType.
+const (
+	WebhookRegister   WebhookChangeType = "register"
+	WebhookUnregister WebhookChangeType = "unregister"
+	// WebhookUpdate is performed by registering the desired webhook and then unregistering the registered one,
+	// so that the webhook isn't lost if registration fails.
+	WebhookUpdate WebhookChangeType = "update"
+)
+
+// WebhookChange represents single change of registered webhooks.
+type WebhookChange struct {
+	Type WebhookChangeType
+	// Webhook is the desired webhook. It's nil for WebhookUnregister.
+	Webhook *Webhook
+	// Registered is the currently registered webhook. It's nil for WebhookRegister.
+	Registered *RegisteredWebhook
+}
+
+func (c WebhookChange) String() string {
+	switch c.Type {
+	case WebhookRegister:
+		return fmt.Sprintf("register %v (%v) %v", c.Webhook.Action, c.Webhook.Type, c.Webhook.URL)
+	case WebhookUnregister:
+		return fmt.Sprintf("unregister %v (%v) %v [%v]", c.Registered.Action, c.Registered.Type, c.Registered.URL, c.Registered.ID)
+	default:
+		return fmt.Sprintf("update %v (%v) %v [%v]", c.Webhook.Action, c.Webhook.Type, c.Webhook.URL, c.Registered.ID)
+	}
+}
+
+// WebhooksPlan describes changes needed to reach the desired state of webhooks.
+type WebhooksPlan struct {
+	Changes []WebhookChange
+	// EnableLicenseWebhooks is set when license webhooks are desired, but disabled for the clientID.
+	EnableLicenseWebhooks bool
+}
+
+// IsEmpty reports whether registered webhooks are already in the desired state.
+func (p *WebhooksPlan) IsEmpty() bool {
+	return len(p.Changes) == 0 && !p.EnableLicenseWebhooks
+}
+
+// String returns human readable description of the plan, one change per line.
+func (p *WebhooksPlan) String() string {
+	if p.IsEmpty() {
+		return "no changes\n"
+	}
+	var sb strings.Builder
+	for _, c := range p.Changes {
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+	}
+	if p.EnableLicenseWebhooks {
+		sb.WriteString("enable license webhooks\n")
+	}
+	return sb.String()
+}
+
+// ReconcileWebhooksOptions are options for ReconcileWebhooks.
+type ReconcileWebhooksOptions struct {
+	// ClientID is the owner of webhooks, see ManageWebhooksDefinitionOptions.
+	ClientID string
+	// DryRun makes ReconcileWebhooks only compute the plan, without applying it.
+	DryRun bool
+}
+
+// ReconcileWebhooks makes webhooks registered for the clientID match given desired webhooks.
+//
+// Webhooks are identified by their action, type and URL. Registered webhooks that are not desired,
+// including duplicates of desired ones, are unregistered. Registered webhooks that differ from desired
+// ones are updated. If any license webhook is desired, license webhooks are enabled as well.
+// Desired webhooks must be unique by their action, type and URL.
+//
+// The returned plan describes the changes, which were applied unless DryRun is set. If applying the plan fails,
+// the plan is returned along with the error; changes preceding the failed one remain applied.
+func (a *API) ReconcileWebhooks(desired []*Webhook, opts *ReconcileWebhooksOptions) (*WebhooksPlan, error) {
+	if opts == nil {
+		opts = &ReconcileWebhooksOptions{}
+	}
+	definitionOpts := &ManageWebhooksDefinitionOptions{ClientID: opts.ClientID}
+	stateOpts := &ManageWebhooksStateOptions{ClientID: opts.ClientID}
+
+	registered, err := a.ListWebhooks(definitionOpts)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list webhooks: %v", err)
+	}
+	plan, err := planWebhooks(desired, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wh := range desired {
+		if wh.Type == "license" {
+			state, err := a.GetLicenseWebhooksState(stateOpts)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't get license webhooks state: %v", err)
+			}
+			plan.EnableLicenseWebhooks = state == nil || !state.Enabled
+			break
+		}
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+	for _, c := range plan.Changes {
+		if c.Webhook != nil {
+			if _, err := a.RegisterWebhook(c.Webhook, definitionOpts); err != nil {
+				return plan, fmt.Errorf("couldn't %v: %v", c, err)
+			}
+		}
+		if c.Registered != nil {
+			if err := a.UnregisterWebhook(c.Registered.ID, definitionOpts); err != nil {
+				return plan, fmt.Errorf("couldn't %v: %v", c, err)
+			}
+		}
+	}
+	if plan.EnableLicenseWebhooks {
+		if err := a.EnableLicenseWebhooks(stateOpts); err != nil {
+			return plan, fmt.Errorf("couldn't enable license webhooks: %v", err)
+		}
+	}
+	return plan, nil
+}
+
+func webhookKey(action, typ, url string) string {
+	return action + " " + typ + " " + url
+}
+
+func planWebhooks(desired []*Webhook, registered []RegisteredWebhook) (*WebhooksPlan, error) {
+	existing := make(map[string][]*RegisteredWebhook)
+	for i := range registered {
+		rw := &registered[i]
+		key := webhookKey(rw.Action, rw.Type, rw.URL)
+		existing[key] = append(existing[key], rw)
+	}
+
+	plan := &WebhooksPlan{}
+	seen := make(map[string]bool)
+	for _, wh := range desired {
+		key := webhookKey(string(wh.Action), wh.Type, wh.URL)
+		if seen[key] {
+			return nil, fmt.Errorf("webhook %v (%v) %v is defined more than once", wh.Action, wh.Type, wh.URL)
+		}
+		seen[key] = true
+		candidates := existing[key]
+		if len(candidates) == 0 {
+			plan.Changes = append(plan.Changes, WebhookChange{Type: WebhookRegister, Webhook: wh})
+			continue
+		}
+		match := 0
+		for i, rw := range candidates {
+			if webhookMatches(wh, rw) {
+				match = i
+				break
+			}
+		}
+		if !webhookMatches(wh, candidates[match]) {
+			plan.Changes = append(plan.Changes, WebhookChange{Type: WebhookUpdate, Webhook: wh, Registered: candidates[match]})
+		}
+		existing[key] = append(candidates[:match:match], candidates[match+1:]...)
+	}
+
+	var unregister []*RegisteredWebhook
+	for _, rws := range existing {
+		unregister = append(unregister, rws...)
+	}
+	sort.Slice(unregister, func(i, j int) bool { return unregister[i].ID < unregister[j].ID })
+	for _, rw := range unregister {
+		plan.Changes = append(plan.Changes, WebhookChange{Type: WebhookUnregister, Registered: rw})
+	}
+	return plan, nil
+}
+
+func webhookMatches(wh *Webhook, rw *RegisteredWebhook) bool {
+	return wh.SecretKey == rw.SecretKey &&
+		wh.Description == rw.Description &&
+		sameStrings(wh.AdditionalData, rw.AdditionalData) &&
+		sameWebhookFilters(wh.Filters, rw.Filters)
+}
+
+// sameWebhookFilters compares filters in JSON form, so that nil and empty filters are equal.
+// Order of values in lists (e.g. source types returned by ListWebhooks) is ignored.
+func sameWebhookFilters(a, b *WebhookFilters) bool {
+	return webhookFiltersJSON(a) == webhookFiltersJSON(b)
+}
+
+func webhookFiltersJSON(f *WebhookFilters) string {
+	if f == nil {
+		return "{}"
+	}
+	normalized := *f
+	normalized.SourceType = sortedStrings(f.SourceType)
+	if f.ChatPresence != nil {
+		presence := *f.ChatPresence
+		if presence.UserIDs != nil {
+			presence.UserIDs = &userIDsFilter{
+				Values:        sortedStrings(presence.UserIDs.Values),
+				ExcludeValues: sortedStrings(presence.UserIDs.ExcludeValues),
+			}
+		}
+		normalized.ChatPresence = &presence
+	}
+	// WebhookFilters consists of strings, booleans, string slices and structs of them,
+	// so it can't fail to marshal.
+	data, _ := json.Marshal(&normalized)
+	return string(data)
+}
+
+func sortedStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
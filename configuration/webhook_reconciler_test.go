@@ -0,0 +1,219 @@
+package configuration_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+)
+
+type routingServerMock struct {
+	responses map[string]string
+	statuses  map[string]int
+	calls     []string
+}
+
+func (s *routingServerMock) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+	body, _ := io.ReadAll(req.Body)
+	s.calls = append(s.calls, method+" "+string(body))
+	resp, exists := s.responses[method]
+	if !exists {
+		resp = `{}`
+	}
+	status, exists := s.statuses[method]
+	if !exists {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(bytes.NewBufferString(resp)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func (s *routingServerMock) called(method string) []string {
+	var calls []string
+	for _, c := range s.calls {
+		if strings.HasPrefix(c, method+" ") {
+			calls = append(calls, strings.TrimPrefix(c, method+" "))
+		}
+	}
+	return calls
+}
+
+const registeredWebhooks = `[
+	{"id": "wh_1", "action": "incoming_chat", "secret_key": "key", "url": "https://example.com/webhooks", "type": "license"},
+	{"id": "wh_2", "action": "incoming_chat", "secret_key": "key", "url": "https://example.com/webhooks", "type": "license"},
+	{"id": "wh_3", "action": "incoming_event", "secret_key": "old_key", "url": "https://example.com/webhooks", "type": "license"},
+	{"id": "wh_4", "action": "chat_deactivated", "secret_key": "key", "url": "https://example.com/webhooks", "type": "bot"}
+]`
+
+var desiredWebhooks = []*configuration.Webhook{
+	{Action: configuration.IncomingChat, SecretKey: "key", URL: "https://example.com/webhooks", Type: "license"},
+	{Action: configuration.IncomingEvent, SecretKey: "key", URL: "https://example.com/webhooks", Type: "license"},
+	{Action: configuration.EventUpdated, SecretKey: "key", URL: "https://example.com/webhooks", Type: "license"},
+}
+
+func TestReconcileWebhooksDryRun(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_webhooks":              registeredWebhooks,
+		"get_license_webhooks_state": `{"license_webhooks_enabled": false}`,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	plan, err := api.ReconcileWebhooks(desiredWebhooks, &configuration.ReconcileWebhooksOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ReconcileWebhooks failed: %v", err)
+	}
+	expected := "update incoming_event (license) https://example.com/webhooks [wh_3]\n" +
+		"register event_updated (license) https://example.com/webhooks\n" +
+		"unregister incoming_chat (license) https://example.com/webhooks [wh_2]\n" +
+		"unregister chat_deactivated (bot) https://example.com/webhooks [wh_4]\n" +
+		"enable license webhooks\n"
+	if plan.String() != expected {
+		t.Errorf("invalid plan:\n%v", plan)
+	}
+	for _, method := range []string{"register_webhook", "unregister_webhook", "enable_license_webhooks"} {
+		if calls := server.called(method); len(calls) != 0 {
+			t.Errorf("%v shouldn't be called in dry run", method)
+		}
+	}
+}
+
+func TestReconcileWebhooksAppliesPlan(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_webhooks":              registeredWebhooks,
+		"get_license_webhooks_state": `{"license_webhooks_enabled": false}`,
+		"register_webhook":           `{"id": "wh_new"}`,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	if _, err := api.ReconcileWebhooks(desiredWebhooks, nil); err != nil {
+		t.Fatalf("ReconcileWebhooks failed: %v", err)
+	}
+	unregistered := server.called("unregister_webhook")
+	if len(unregistered) != 3 || !strings.Contains(unregistered[0], `"wh_3"`) {
+		t.Errorf("invalid unregistered webhooks: %v", unregistered)
+	}
+	registered := server.called("register_webhook")
+	if len(registered) != 2 || !strings.Contains(registered[0], `"incoming_event"`) || !strings.Contains(registered[1], `"event_updated"`) {
+		t.Errorf("invalid registered webhooks: %v", registered)
+	}
+	if len(server.called("enable_license_webhooks")) != 1 {
+		t.Error("license webhooks should be enabled")
+	}
+}
+
+func TestReconcileWebhooksInDesiredState(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_webhooks": `[
+			{"id": "wh_1", "action": "incoming_chat", "secret_key": "key", "url": "https://example.com/webhooks", "type": "license", "filters": {}},
+			{"id": "wh_3", "action": "incoming_event", "secret_key": "old_key", "url": "https://example.com/webhooks", "type": "license"},
+			{"id": "wh_4", "action": "chat_deactivated", "secret_key": "key", "url": "https://example.com/webhooks", "type": "bot"}
+		]`,
+		"get_license_webhooks_state": `{"license_webhooks_enabled": true}`,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	desired := []*configuration.Webhook{
+		{Action: configuration.IncomingChat, SecretKey: "key", URL: "https://example.com/webhooks", Type: "license"},
+		{Action: configuration.IncomingEvent, SecretKey: "old_key", URL: "https://example.com/webhooks", Type: "license", Filters: &configuration.WebhookFilters{}},
+		{Action: configuration.ChatDeactivated, SecretKey: "key", URL: "https://example.com/webhooks", Type: "bot"},
+	}
+	plan, err := api.ReconcileWebhooks(desired, nil)
+	if err != nil {
+		t.Fatalf("ReconcileWebhooks failed: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("plan should be empty:\n%v", plan)
+	}
+}
+
+func TestReconcileWebhooksIgnoresOrderOfFilterValues(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_webhooks": `[{
+			"id": "wh_1", "action": "incoming_event", "secret_key": "key", "url": "https://example.com/webhooks", "type": "license",
+			"filters": {"source_type": ["other", "my_client"], "chat_presence": {"user_ids": {"values": ["b", "a"]}}}
+		}]`,
+		"get_license_webhooks_state": `{"license_webhooks_enabled": true}`,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	filters := &configuration.WebhookFilters{
+		SourceType:   []string{"my_client", "other"},
+		ChatPresence: configuration.NewChatPresenceFilter().WithUserIDs([]string{"a", "b"}, true),
+	}
+	desired := []*configuration.Webhook{
+		{Action: configuration.IncomingEvent, SecretKey: "key", URL: "https://example.com/webhooks", Type: "license", Filters: filters},
+	}
+	plan, err := api.ReconcileWebhooks(desired, &configuration.ReconcileWebhooksOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ReconcileWebhooks failed: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("plan should be empty:\n%v", plan)
+	}
+	if filters.SourceType[0] != "my_client" || filters.ChatPresence.UserIDs.Values[0] != "a" {
+		t.Errorf("desired filters shouldn't be modified: %+v", filters)
+	}
+}
+
+func TestReconcileWebhooksRejectsDuplicatedWebhooks(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_webhooks": registeredWebhooks,
+	}}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	desired := []*configuration.Webhook{
+		{Action: configuration.IncomingChat, SecretKey: "key", URL: "https://example.com/webhooks", Type: "bot"},
+		{Action: configuration.IncomingChat, SecretKey: "other_key", URL: "https://example.com/webhooks", Type: "bot"},
+	}
+	if _, err := api.ReconcileWebhooks(desired, nil); err == nil {
+		t.Error("duplicated webhooks should be rejected")
+	}
+	if calls := server.called("register_webhook"); len(calls) != 0 {
+		t.Errorf("no webhook should be registered: %v", calls)
+	}
+}
+
+func TestReconcileWebhooksKeepsWebhookIfUpdateFails(t *testing.T) {
+	server := &routingServerMock{responses: map[string]string{
+		"list_webhooks":    registeredWebhooks,
+		"register_webhook": `{"error": {"type": "validation", "message": "invalid url"}}`,
+	}}
+	server.statuses = map[string]int{"register_webhook": http.StatusBadRequest}
+	api, err := configuration.NewAPI(stubTokenGetter, &http.Client{Transport: server}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+
+	desired := []*configuration.Webhook{
+		{Action: configuration.IncomingEvent, SecretKey: "key", URL: "https://example.com/webhooks", Type: "license"},
+	}
+	if _, err := api.ReconcileWebhooks(desired, &configuration.ReconcileWebhooksOptions{}); err == nil {
+		t.Fatal("ReconcileWebhooks should fail")
+	}
+	if calls := server.called("unregister_webhook"); len(calls) != 0 {
+		t.Errorf("webhook shouldn't be unregistered when registration fails: %v", calls)
+	}
+}
@@ -2,12 +2,14 @@ package customer
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/livechat/lc-sdk-go/v6/authorization"
 	i "github.com/livechat/lc-sdk-go/v6/internal"
+	"github.com/livechat/lc-sdk-go/v6/internal/images"
 )
 
 type customerAPI interface {
@@ -23,6 +25,7 @@ type customerAPI interface {
 // See this package's package overview docs for details on the service.
 type API struct {
 	customerAPI
+	eventValidation bool
 }
 
 func CustomerEndpointGenerator(r i.HTTPEndpointGenerator) i.HTTPEndpointGenerator {
@@ -43,7 +46,37 @@ func NewAPI(t authorization.TokenGetter, client *http.Client, clientID string) (
 	if err != nil {
 		return nil, err
 	}
-	return &API{api}, nil
+	return &API{customerAPI: api, eventValidation: true}, nil
+}
+
+// SetEventValidation allows to turn off (or back on) validation of event fields performed by SendEvent,
+// StartChat and ResumeChat before the request is sent. Check of supported event types is always performed.
+//
+// Validation is enabled by default, see ValidateEventFields for details.
+func (a *API) SetEventValidation(enabled bool) {
+	a.eventValidation = enabled
+}
+
+func (a *API) validateEvent(e interface{}) error {
+	if !a.eventValidation {
+		return ValidateEvent(e)
+	}
+	return ValidateEventFields(e)
+}
+
+func (a *API) validateInitialChat(chat *InitialChat) error {
+	if err := chat.Validate(); err != nil {
+		return err
+	}
+	if !a.eventValidation || chat.Thread == nil {
+		return nil
+	}
+	for _, e := range chat.Thread.Events {
+		if err := ValidateEventFields(e); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // StartChat starts new chat with access, properties and initial thread as defined in initialChat.
@@ -55,7 +88,7 @@ func (a *API) StartChat(initialChat *InitialChat, continuous, active bool) (chat
 		Active:     active,
 	}
 
-	if err := initialChat.Validate(); err != nil {
+	if err := a.validateInitialChat(initialChat); err != nil {
 		return "", "", nil, err
 	}
 	var resp startChatResponse
@@ -96,9 +129,10 @@ func (a *API) SendSystemMessage(chatID, text, messageType string, textVars map[s
 // SendEvent sends event of supported type to given chat.
 // It returns event ID.
 //
-// Supported event types are: event, file, message, rich_message and system_message.
+// Supported event types are: event, custom, file, form, message, rich_message and system_message.
+// Unless disabled with SetEventValidation, event fields are validated before sending with ValidateEventFields.
 func (a *API) SendEvent(chatID string, e interface{}, attachToLastThread bool) (string, error) {
-	if err := ValidateEvent(e); err != nil {
+	if err := a.validateEvent(e); err != nil {
 		return "", err
 	}
 
@@ -118,7 +152,7 @@ func (a *API) SendEvent(chatID string, e interface{}, attachToLastThread bool) (
 func (a *API) ResumeChat(initialChat *InitialChat, continuous, active bool) (threadID string, eventIDs []string, err error) {
 	var resp resumeChatResponse
 
-	if err := initialChat.Validate(); err != nil {
+	if err := a.validateInitialChat(initialChat); err != nil {
 		return "", nil, err
 	}
 
@@ -179,6 +213,55 @@ func (a *API) DeactivateChat(chatID string) error {
 	}, &emptyResponse{})
 }
 
+// SendFile uploads file with given name and content read from r, then sends it to given chat as file event.
+// It returns event ID.
+//
+// Content type is detected from the file's content and name, and for PNG, JPEG and GIF images their width
+// and height are filled in. Uploaded file is sent immediately, as its URL expires after about 24 hours.
+//
+// Files larger than 10 MB are rejected. File event is validated with ValidateEventFields before the file
+// is uploaded, so invalid options don't leave unused uploads behind.
+func (a *API) SendFile(chatID, name string, r io.Reader, opts *SendFileOptions) (string, error) {
+	if opts == nil {
+		opts = &SendFileOptions{}
+	}
+	data, err := i.ReadFile(r)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read file %q: %w", name, err)
+	}
+
+	f := &File{
+		Event: Event{
+			Type:       "file",
+			Recipients: string(opts.Recipients),
+		},
+		ContentType:     opts.ContentType,
+		Name:            name,
+		Size:            len(data),
+		AlternativeText: opts.AlternativeText,
+	}
+	if f.ContentType == "" {
+		f.ContentType = i.DetectContentType(name, data)
+	}
+	if width, height, ok := images.Size(data); ok {
+		f.Width, f.Height = width, height
+	}
+
+	f.URL = i.FilePlaceholderURL
+	if err := ValidateEventFields(f); err != nil {
+		return "", fmt.Errorf("couldn't send file %q: %w", name, err)
+	}
+	f.URL, err = a.UploadFile(name, data)
+	if err != nil {
+		return "", fmt.Errorf("couldn't upload file %q: %w", name, err)
+	}
+	eventID, err := a.SendEvent(chatID, f, opts.AttachToLastThread)
+	if err != nil {
+		return "", fmt.Errorf("couldn't send file %q: %w", name, err)
+	}
+	return eventID, nil
+}
+
 // SendRichMessagePostback sends postback for given rich message event.
 func (a *API) SendRichMessagePostback(chatID, threadID, eventID, postbackID string, toggled bool) error {
 	return a.Call("send_rich_message_postback", &sendRichMessagePostbackRequest{
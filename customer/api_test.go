@@ -2,8 +2,11 @@ package customer_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 	"testing"
 	"time"
@@ -447,6 +450,8 @@ func TestStartChatShouldReturnDataReceivedFromCustomerAPI(t *testing.T) {
 	}
 
 	m := &customer.Message{
+		Event: customer.Event{Type: "message"},
+		Text:  "Hello",
 		Postback: &customer.Postback{
 			ID:    "123",
 			Value: "abc",
@@ -488,6 +493,116 @@ func TestSendEventShouldReturnDataReceivedFromCustomerAPI(t *testing.T) {
 	}
 }
 
+func TestSendEventShouldValidateEventFieldsUnlessDisabled(t *testing.T) {
+	requests := 0
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		requests++
+		return createMockedResponder(t, "send_event")(req)
+	})
+
+	api, err := customer.NewAPI(stubTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	event := &customer.Message{
+		Event: customer.Event{Type: "message", Visibility: "all"},
+		Text:  " ",
+	}
+	_, rErr := api.SendEvent("stubChatID", event, false)
+	var validationErr *customer.EventValidationError
+	if !errors.As(rErr, &validationErr) || len(validationErr.Fields) != 2 {
+		t.Fatalf("Invalid error: %v", rErr)
+	}
+	if validationErr.Fields[0].Field != "visibility" || validationErr.Fields[1].Field != "text" {
+		t.Errorf("Invalid fields: %v", validationErr.Fields)
+	}
+	if requests != 0 {
+		t.Errorf("Invalid event shouldn't be sent")
+	}
+
+	api.SetEventValidation(false)
+	if _, rErr := api.SendEvent("stubChatID", event, false); rErr != nil {
+		t.Errorf("SendEvent failed: %v", rErr)
+	}
+	if requests != 1 {
+		t.Errorf("Event should be sent with validation disabled")
+	}
+}
+
+func TestSendEventShouldAcceptBuiltRichMessage(t *testing.T) {
+	client := NewTestClient(createMockedResponder(t, "send_event"))
+
+	api, err := customer.NewAPI(stubTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	rm, err := customer.NewRichMessageBuilder(customer.TemplateQuickReplies).
+		WithElement("Pick one", "").
+		WithButton(customer.RichMessageButton{Text: "Yes", Type: customer.ButtonTypeMessage, PostbackID: "yes"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if _, rErr := api.SendEvent("stubChatID", rm, false); rErr != nil {
+		t.Errorf("SendEvent failed: %v", rErr)
+	}
+}
+
+func TestSendFileShouldUploadAndSendFileEvent(t *testing.T) {
+	var sent struct {
+		Event customer.File `json:"event"`
+	}
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		action := path.Base(req.URL.Path)
+		if action == "send_event" {
+			json.NewDecoder(req.Body).Decode(&sent)
+		}
+		return createMockedResponder(t, action)(req)
+	})
+
+	api, err := customer.NewAPI(stubTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	opts := &customer.SendFileOptions{Recipients: customer.Agents}
+	if _, rErr := api.SendFile("stubChatID", "notes.txt", strings.NewReader("notes"), opts); rErr != nil {
+		t.Fatalf("SendFile failed: %v", rErr)
+	}
+	f := sent.Event
+	if f.Recipients != "agents" || f.ContentType != "text/plain; charset=utf-8" || f.Size != 5 || f.Width != 0 {
+		t.Errorf("Invalid file event: %+v", f)
+	}
+}
+
+func TestSendFileShouldNotUploadRejectedFile(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		t.Errorf("Unexpected request: %v", path.Base(req.URL.Path))
+		return createMockedResponder(t, path.Base(req.URL.Path))(req)
+	})
+
+	api, err := customer.NewAPI(stubTokenGetter, client, "client_id")
+	if err != nil {
+		t.Error("API creation failed")
+	}
+
+	opts := &customer.SendFileOptions{Recipients: "customers"}
+	_, rErr := api.SendFile("stubChatID", "notes.txt", strings.NewReader("notes"), opts)
+	var validationErr *customer.EventValidationError
+	if !errors.As(rErr, &validationErr) || validationErr.Fields[0].Field != "recipients" {
+		t.Errorf("Invalid error: %v", rErr)
+	}
+
+	large := strings.NewReader(strings.Repeat("a", 10<<20+1))
+	_, rErr = api.SendFile("stubChatID", "large.txt", large, nil)
+	if rErr == nil || rErr.Error() != `couldn't read file "large.txt": file exceeds 10485760 bytes` {
+		t.Errorf("Invalid error: %v", rErr)
+	}
+}
+
 func TestSendMessageShouldReturnDataReceivedFromCustomerAPI(t *testing.T) {
 	client := NewTestClient(createMockedResponder(t, "send_event"))
 
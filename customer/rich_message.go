@@ -0,0 +1,46 @@
+package customer
+
+import "github.com/livechat/lc-sdk-go/v6/objects"
+
+// RichMessageBuilder allows to build RichMessage, see package objects for its documentation.
+type RichMessageBuilder = objects.RichMessageBuilder
+
+// Rich message templates supported by RichMessageBuilder.
+const (
+	TemplateCards        = objects.TemplateCards
+	TemplateQuickReplies = objects.TemplateQuickReplies
+	TemplateSticker      = objects.TemplateSticker
+)
+
+// Types of rich message buttons.
+const (
+	ButtonTypeWebview = objects.ButtonTypeWebview
+	ButtonTypeMessage = objects.ButtonTypeMessage
+	ButtonTypeURL     = objects.ButtonTypeURL
+	ButtonTypePhone   = objects.ButtonTypePhone
+	ButtonTypeCancel  = objects.ButtonTypeCancel
+)
+
+// Heights of webview opened by rich message button of "webview" type.
+const (
+	WebviewHeightCompact = objects.WebviewHeightCompact
+	WebviewHeightFull    = objects.WebviewHeightFull
+	WebviewHeightTall    = objects.WebviewHeightTall
+)
+
+// Targets of link opened by rich message button of "url" type.
+const (
+	ButtonTargetNew     = objects.ButtonTargetNew
+	ButtonTargetCurrent = objects.ButtonTargetCurrent
+)
+
+// NewRichMessageBuilder creates RichMessageBuilder for given template.
+func NewRichMessageBuilder(templateID string) *RichMessageBuilder {
+	return objects.NewRichMessageBuilder(templateID)
+}
+
+// ValidateRichMessage checks if RichMessage conforms to its template: number of elements and buttons,
+// button types and their options, postback IDs and image fields.
+func ValidateRichMessage(rm *RichMessage) error {
+	return objects.ValidateRichMessage(rm)
+}
@@ -0,0 +1,124 @@
+// Package events implements transport-agnostic source of LiveChat chat events.
+//
+// Events received with webhooks (see WebhookSource) or fetched by polling (see PollingSource)
+// are normalized to Event, so that the code handling them doesn't depend on the transport.
+package events
+
+import (
+	"context"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+// Kind describes what happened in the chat.
+type Kind string
+
+// Possible values of Kind.
+const (
+	IncomingChat        Kind = "incoming_chat"
+	IncomingEvent       Kind = "incoming_event"
+	ChatDeactivated     Kind = "chat_deactivated"
+	ChatTransferred     Kind = "chat_transferred"
+	UserAddedToChat     Kind = "user_added_to_chat"
+	UserRemovedFromChat Kind = "user_removed_from_chat"
+)
+
+// Kinds returns all supported event kinds.
+func Kinds() []Kind {
+	return []Kind{IncomingChat, IncomingEvent, ChatDeactivated, ChatTransferred, UserAddedToChat, UserRemovedFromChat}
+}
+
+// Event represents normalized chat event.
+type Event struct {
+	Kind     Kind
+	ChatID   string
+	ThreadID string
+	// Payload describes the event in detail. It's one of *webhooks.IncomingChat, *webhooks.IncomingEvent,
+	// *webhooks.ChatDeactivated, *webhooks.ChatTransferred, *webhooks.UserAddedToChat or *webhooks.UserRemovedFromChat,
+	// depending on Kind.
+	Payload interface{}
+}
+
+// IncomingChat returns Event's payload if its Kind is IncomingChat, nil otherwise.
+func (e *Event) IncomingChat() *webhooks.IncomingChat {
+	p, _ := e.Payload.(*webhooks.IncomingChat)
+	return p
+}
+
+// IncomingEvent returns Event's payload if its Kind is IncomingEvent, nil otherwise.
+func (e *Event) IncomingEvent() *webhooks.IncomingEvent {
+	p, _ := e.Payload.(*webhooks.IncomingEvent)
+	return p
+}
+
+// ChatDeactivated returns Event's payload if its Kind is ChatDeactivated, nil otherwise.
+func (e *Event) ChatDeactivated() *webhooks.ChatDeactivated {
+	p, _ := e.Payload.(*webhooks.ChatDeactivated)
+	return p
+}
+
+// ChatTransferred returns Event's payload if its Kind is ChatTransferred, nil otherwise.
+func (e *Event) ChatTransferred() *webhooks.ChatTransferred {
+	p, _ := e.Payload.(*webhooks.ChatTransferred)
+	return p
+}
+
+// UserAddedToChat returns Event's payload if its Kind is UserAddedToChat, nil otherwise.
+func (e *Event) UserAddedToChat() *webhooks.UserAddedToChat {
+	p, _ := e.Payload.(*webhooks.UserAddedToChat)
+	return p
+}
+
+// UserRemovedFromChat returns Event's payload if its Kind is UserRemovedFromChat, nil otherwise.
+func (e *Event) UserRemovedFromChat() *webhooks.UserRemovedFromChat {
+	p, _ := e.Payload.(*webhooks.UserRemovedFromChat)
+	return p
+}
+
+// FromWebhook normalizes webhook with decoded payload to Event.
+// It returns false if webhook doesn't describe any of supported event kinds.
+func FromWebhook(wh *webhooks.Webhook) (*Event, bool) {
+	switch p := wh.Payload.(type) {
+	case *webhooks.IncomingChat:
+		e := &Event{Kind: IncomingChat, ChatID: p.Chat.ID, Payload: p}
+		if p.Chat.Thread != nil {
+			e.ThreadID = p.Chat.Thread.ID
+		} else if len(p.Chat.Threads) > 0 {
+			e.ThreadID = p.Chat.Threads[len(p.Chat.Threads)-1].ID
+		}
+		return e, true
+	case *webhooks.IncomingEvent:
+		return &Event{Kind: IncomingEvent, ChatID: p.ChatID, ThreadID: p.ThreadID, Payload: p}, true
+	case *webhooks.ChatDeactivated:
+		return &Event{Kind: ChatDeactivated, ChatID: p.ChatID, ThreadID: p.ThreadID, Payload: p}, true
+	case *webhooks.ChatTransferred:
+		return &Event{Kind: ChatTransferred, ChatID: p.ChatID, ThreadID: p.ThreadID, Payload: p}, true
+	case *webhooks.UserAddedToChat:
+		return &Event{Kind: UserAddedToChat, ChatID: p.ChatID, ThreadID: p.ThreadID, Payload: p}, true
+	case *webhooks.UserRemovedFromChat:
+		return &Event{Kind: UserRemovedFromChat, ChatID: p.ChatID, ThreadID: p.ThreadID, Payload: p}, true
+	}
+	return nil, false
+}
+
+func webhookActions() []configuration.WebhookAction {
+	return []configuration.WebhookAction{
+		configuration.IncomingChat,
+		configuration.IncomingEvent,
+		configuration.ChatDeactivated,
+		configuration.ChatTransferred,
+		configuration.UserAddedToChat,
+		configuration.UserRemovedFromChat,
+	}
+}
+
+// Handler processes events emitted by Source. Returning an error means the event wasn't processed
+// and Source should deliver it again, if the transport allows it.
+type Handler func(ctx context.Context, e *Event) error
+
+// Source emits normalized chat events.
+type Source interface {
+	// Run passes events to given Handler until ctx is done or Source fails.
+	Run(ctx context.Context, h Handler) error
+}
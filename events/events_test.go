@@ -0,0 +1,145 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/events"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+	"github.com/livechat/lc-sdk-go/v6/webhooks/webhooktest"
+)
+
+func TestWebhookSourceEmitsEventsOfAllKinds(t *testing.T) {
+	cfg := webhooks.NewConfiguration()
+	source := events.NewWebhookSource(cfg, webhooktest.DefaultSecretKey)
+	h := webhooks.NewWebhookHandler(cfg)
+
+	body, _ := webhooktest.Fixture(configuration.IncomingEvent)
+	if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("webhooks should be rejected while source isn't running: %v", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	received := make(map[events.Kind]*events.Event)
+	done := make(chan error)
+	go func() {
+		done <- source.Run(ctx, func(ctx context.Context, e *events.Event) error {
+			mu.Lock()
+			received[e.Kind] = e
+			mu.Unlock()
+			return nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	for _, kind := range events.Kinds() {
+		body, _ := webhooktest.Fixture(configuration.WebhookAction(kind))
+		if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusOK {
+			t.Errorf("invalid code for %v: %v", kind, resp.StatusCode)
+		}
+	}
+	cancel()
+	<-done
+
+	for _, kind := range events.Kinds() {
+		e := received[kind]
+		if e == nil {
+			t.Errorf("missing event: %v", kind)
+			continue
+		}
+		if e.ChatID == "" {
+			t.Errorf("missing chat ID of %v", kind)
+		}
+	}
+	if e := received[events.IncomingEvent]; e.IncomingEvent().Event.ID != "PZ070E0W1B_3" || e.ThreadID != "PZ070E0W1B" {
+		t.Errorf("invalid incoming event: %+v", e)
+	}
+	if e := received[events.IncomingChat]; e.IncomingChat() == nil || e.ThreadID != "PZ070E0W1B" {
+		t.Errorf("invalid incoming chat: %+v", e)
+	}
+}
+
+type fakePoller struct {
+	batches   [][]*webhooks.Webhook
+	committed int
+}
+
+func (p *fakePoller) Poll(ctx context.Context) ([]*webhooks.Webhook, error) {
+	if p.committed >= len(p.batches) {
+		return nil, nil
+	}
+	return p.batches[p.committed], nil
+}
+
+func (p *fakePoller) Commit() error {
+	if p.committed < len(p.batches) {
+		p.committed++
+	}
+	return nil
+}
+
+func TestPollingSourceRepollsEventsUntilProcessed(t *testing.T) {
+	poller := &fakePoller{batches: [][]*webhooks.Webhook{
+		{
+			{Action: "incoming_event", Payload: &webhooks.IncomingEvent{ChatID: "chat_1"}},
+			{Action: "chat_deactivated", Payload: &webhooks.ChatDeactivated{ChatID: "chat_1"}},
+		},
+		{
+			{Action: "thread_tagged", Payload: &webhooks.ThreadTagged{ChatID: "chat_1"}},
+			{Action: "incoming_event", Payload: &webhooks.IncomingEvent{ChatID: "chat_2"}},
+		},
+	}}
+	source := events.NewPollingSource(poller, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var received []string
+	failed := false
+	err := source.Run(ctx, func(ctx context.Context, e *events.Event) error {
+		if e.Kind == events.ChatDeactivated && !failed {
+			failed = true
+			return errors.New("handler failed")
+		}
+		received = append(received, string(e.Kind)+" "+e.ChatID)
+		if len(received) == 4 {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("invalid error: %v", err)
+	}
+
+	expected := []string{"incoming_event chat_1", "incoming_event chat_1", "chat_deactivated chat_1", "incoming_event chat_2"}
+	if len(received) != len(expected) {
+		t.Fatalf("invalid events: %v", received)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Errorf("invalid events: %v", received)
+		}
+	}
+}
+
+func TestPollingSourceWithoutIntervalUsesDefault(t *testing.T) {
+	poller := &fakePoller{batches: [][]*webhooks.Webhook{
+		{{Action: "incoming_event", Payload: &webhooks.IncomingEvent{ChatID: "chat_1"}}},
+	}}
+	source := events.NewPollingSource(poller, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := source.Run(ctx, func(ctx context.Context, e *events.Event) error {
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("invalid error: %v", err)
+	}
+}
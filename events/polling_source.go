@@ -0,0 +1,81 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+// Poller fetches webhooks that would have been sent by LiveChat since the last committed poll.
+type Poller interface {
+	// Poll returns webhooks with decoded payloads, in order of their occurrence.
+	Poll(ctx context.Context) ([]*webhooks.Webhook, error)
+	// Commit marks webhooks returned by the last Poll as processed, so that they aren't returned again.
+	Commit() error
+}
+
+// DefaultPollingInterval is interval used by PollingSource created with non-positive interval.
+const DefaultPollingInterval = 10 * time.Second
+
+// PollingSource is Source of events fetched periodically with Poller.
+//
+// Events are delivered at least once: if Handler returns an error, the whole batch of polled events
+// is polled again in the next round, so events already handled in that batch are delivered again.
+// Handler should be idempotent (eg. by deduplicating events by their ID).
+type PollingSource struct {
+	poller   Poller
+	interval time.Duration
+}
+
+// NewPollingSource creates PollingSource, which polls for events with given interval.
+// If interval isn't positive, DefaultPollingInterval is used.
+func NewPollingSource(p Poller, interval time.Duration) *PollingSource {
+	if interval <= 0 {
+		interval = DefaultPollingInterval
+	}
+	return &PollingSource{
+		poller:   p,
+		interval: interval,
+	}
+}
+
+// Run implements Source interface.
+//
+// Polled events are committed once all of them are processed. If Handler returns an error, remaining events are
+// skipped and the whole batch is polled again in the next round, see PollingSource. Run returns when ctx is done or Poller fails.
+func (s *PollingSource) Run(ctx context.Context, h Handler) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.poll(ctx, h); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PollingSource) poll(ctx context.Context, h Handler) error {
+	whs, err := s.poller.Poll(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't poll events: %v", err)
+	}
+	for _, wh := range whs {
+		e, ok := FromWebhook(wh)
+		if !ok {
+			continue
+		}
+		if err := h(ctx, e); err != nil {
+			return nil
+		}
+	}
+	if err := s.poller.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit polled events: %v", err)
+	}
+	return nil
+}
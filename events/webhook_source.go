@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+// ErrSourceNotRunning is returned to WebhookHandler for webhooks received while WebhookSource isn't running,
+// so that LiveChat delivers them again later.
+var ErrSourceNotRunning = errors.New("event source is not running")
+
+// WebhookSource is Source of events received with webhooks.
+type WebhookSource struct {
+	mu      sync.RWMutex
+	handler Handler
+}
+
+// NewWebhookSource creates WebhookSource and attaches it to given Configuration as Handler of webhook actions
+// of all supported event kinds. Webhooks are validated with given secretKey, as in Configuration.WithAction.
+func NewWebhookSource(cfg *webhooks.Configuration, secretKey string) *WebhookSource {
+	s := &WebhookSource{}
+	cfg.Update(func(cfg *webhooks.Configuration) {
+		for _, action := range webhookActions() {
+			cfg.WithAction(action, s.handle, secretKey)
+		}
+	})
+	return s
+}
+
+// Run implements Source interface. Only one Run can be active at a time.
+func (s *WebhookSource) Run(ctx context.Context, h Handler) error {
+	s.mu.Lock()
+	if s.handler != nil {
+		s.mu.Unlock()
+		return errors.New("event source is already running")
+	}
+	s.handler = h
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	s.handler = nil
+	s.mu.Unlock()
+	return ctx.Err()
+}
+
+func (s *WebhookSource) handle(ctx context.Context, wh *webhooks.Webhook) error {
+	s.mu.RLock()
+	h := s.handler
+	s.mu.RUnlock()
+	if h == nil {
+		return ErrSourceNotRunning
+	}
+	e, ok := FromWebhook(wh)
+	if !ok {
+		return nil
+	}
+	return h(ctx, e)
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/livechat/lc-sdk-go/v6/configuration"
 	"github.com/livechat/lc-sdk-go/v6/webhooks"
 )
 
@@ -34,7 +35,7 @@ func main() {
 	installationHandler := NewInstallationHandler(cfg, tr, as)
 	incominEventHandler := NewIncomingEventHandler(cfg, tr)
 	whConfig := webhooks.NewConfiguration().
-		WithAction("incoming_event", incominEventHandler.Handle, cfg.WebhookSecret).
+		WithAction(configuration.IncomingEvent, incominEventHandler.Handle, cfg.WebhookSecret).
 		WithErrorHandler(func(w http.ResponseWriter, err string, statusCode int) {
 			fmt.Printf("Error when handling webhook: %v\n", err)
 			w.WriteHeader(http.StatusOK)
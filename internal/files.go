@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// MaxFileSize is the maximum size of file accepted by upload_file method.
+const MaxFileSize = 10 << 20
+
+// FilePlaceholderURL is used as URL of file event validated before the file is uploaded.
+const FilePlaceholderURL = "https://cdn.livechat-files.com/placeholder"
+
+// ReadFile reads content of file to be uploaded. It returns error if the file exceeds MaxFileSize,
+// without reading more than needed to find it out.
+func ReadFile(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("file exceeds %v bytes", MaxFileSize)
+	}
+	return data, nil
+}
+
+// DetectContentType returns MIME type of file with given name and content. It's sniffed from the content,
+// unless it's too generic, in which case the type associated with file's extension is used.
+func DetectContentType(name string, data []byte) string {
+	contentType := http.DetectContentType(data)
+	if contentType != "application/octet-stream" && !strings.HasPrefix(contentType, "text/plain") {
+		return contentType
+	}
+	if byExtension := mime.TypeByExtension(filepath.Ext(name)); byExtension != "" {
+		return byExtension
+	}
+	return contentType
+}
@@ -0,0 +1,22 @@
+// Package images reads dimensions of images attached to file events.
+//
+// Importing it registers GIF, JPEG and PNG decoders for image.DecodeConfig, so it should be imported only
+// by packages sending files.
+package images
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"  // register GIF format for image.DecodeConfig
+	_ "image/jpeg" // register JPEG format for image.DecodeConfig
+	_ "image/png"  // register PNG format for image.DecodeConfig
+)
+
+// Size returns dimensions of PNG, JPEG or GIF image. It returns false for other formats or malformed images.
+func Size(data []byte) (width, height int, ok bool) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, false
+	}
+	return cfg.Width, cfg.Height, true
+}
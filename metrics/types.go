@@ -7,3 +7,17 @@ type APICallStats struct {
 	ExecutionTime time.Duration
 	Success       bool
 }
+
+// WebhookStats represents statistics of single webhook received by WebhookHandler.
+type WebhookStats struct {
+	// Action is empty if webhook couldn't be decoded.
+	Action string
+	// ErrorCode is a reason of webhook processing failure (see webhooks.ErrorCode), empty on success.
+	ErrorCode      string
+	DecodeFailed   bool
+	SecretMismatch bool
+	HandlerFailed  bool
+	// HandlerExecutionTime is zero if Handler wasn't called.
+	HandlerExecutionTime time.Duration
+	Success              bool
+}
@@ -0,0 +1,112 @@
+package objects
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Properties represents LiveChat properties in form of property_namespace -> property -> value.
+type Properties map[string]map[string]interface{}
+
+// Chat represents LiveChat chat.
+type Chat struct {
+	ID         string     `json:"id,omitempty"`
+	Properties Properties `json:"properties,omitempty"`
+	Access     *Access    `json:"access,omitempty"`
+	Thread     *Thread    `json:"thread,omitempty"`
+	Threads    []Thread   `json:"threads,omitempty"`
+	IsFollowed bool       `json:"is_followed,omitempty"`
+	Agents     map[string]*Agent
+	Customers  map[string]*Customer
+}
+
+// Users function returns combined list of Chat's Agents and Customers.
+func (c *Chat) Users() []*User {
+	u := make([]*User, 0, len(c.Agents)+len(c.Customers))
+	for _, a := range c.Agents {
+		u = append(u, a.User)
+	}
+	for _, cu := range c.Customers {
+		u = append(u, cu.User)
+	}
+
+	return u
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface for Chat.
+func (c *Chat) UnmarshalJSON(data []byte) error {
+	type ChatAlias Chat
+	var cs struct {
+		*ChatAlias
+		Users []json.RawMessage `json:"users"`
+	}
+
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return err
+	}
+
+	var t struct {
+		Type string `json:"type"`
+	}
+
+	*c = (Chat)(*cs.ChatAlias)
+	c.Agents = make(map[string]*Agent)
+	c.Customers = make(map[string]*Customer)
+	for _, u := range cs.Users {
+		if err := json.Unmarshal(u, &t); err != nil {
+			return err
+		}
+		switch t.Type {
+		case "agent":
+			var a Agent
+			if err := json.Unmarshal(u, &a); err != nil {
+				return err
+			}
+			c.Agents[a.ID] = &a
+		case "customer":
+			var cu Customer
+			if err := json.Unmarshal(u, &cu); err != nil {
+				return err
+			}
+			c.Customers[cu.ID] = &cu
+		}
+	}
+
+	return nil
+}
+
+// Thread represents LiveChat chat thread
+type Thread struct {
+	ID                        string     `json:"id"`
+	Active                    bool       `json:"active"`
+	UserIDs                   []string   `json:"user_ids"`
+	RestrictedAccess          string     `json:"restricted_access"`
+	Properties                Properties `json:"properties"`
+	Access                    *Access    `json:"access"`
+	Tags                      []string   `json:"tags,omitempty"`
+	Events                    []*Event   `json:"events"`
+	PreviousThreadID          string     `json:"previous_thread_id"`
+	NextThreadID              string     `json:"next_thread_id"`
+	CreatedAt                 time.Time  `json:"created_at"`
+	PreviousAccesibleThreadID string     `json:"previous_accessible_thread_id,omitempty"`
+	NextAccessibleThreadID    string     `json:"next_accessible_thread_id,omitempty"`
+	Queue                     *Queue     `json:"queue,omitempty"`
+	QueuesDuration            *int       `json:"queues_duration,omitempty"`
+	CustomerVisit             *struct {
+		IP          string      `json:"ip"`
+		UserAgent   string      `json:"user_agent"`
+		Geolocation Geolocation `json:"geolocation"`
+	} `json:"customer_visit,omitempty"`
+}
+
+// Access represents LiveChat chat and thread access
+type Access struct {
+	GroupIDs []int `json:"group_ids"`
+}
+
+// Queue represents position of a thread in a queue
+type Queue struct {
+	Position int       `json:"position"`
+	WaitTime int       `json:"wait_time"`
+	QueuedAt time.Time `json:"queued_at"`
+}
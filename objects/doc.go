@@ -0,0 +1,7 @@
+// Package objects provides structures of LiveChat domain objects shared by Agent Chat API, Customer Chat API
+// and webhooks.
+//
+// Packages agent, customer and webhooks expose these structures under their own names via type aliases,
+// so values can be passed between them without conversion. Structures which differ between the APIs
+// are defined in particular packages, along with functions converting them to and from their shared counterparts.
+package objects
@@ -0,0 +1,211 @@
+package objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaxTextSize is the maximum size of message text in bytes, checked by ValidateAgentEvent and ValidateCustomerEvent.
+// Chat APIs document it as "max raw text size is 16 KB" for the text field of the message event, see
+// https://platform.text.com/docs/messaging/agent-chat-api/data-structures#message.
+const MaxTextSize = 16 * 1024
+
+// EventFieldError describes single invalid field of outgoing event.
+type EventFieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e EventFieldError) String() string {
+	return e.Field + ": " + e.Reason
+}
+
+// EventValidationError is returned when outgoing event has invalid fields.
+type EventValidationError struct {
+	EventType string
+	Fields    []EventFieldError
+}
+
+func (e *EventValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		reasons[i] = f.String()
+	}
+	return fmt.Sprintf("invalid %v event: %v", e.EventType, strings.Join(reasons, "; "))
+}
+
+// ValidateAgentEvent checks fields of event sent with Agent Chat API. Visibility of the event is set
+// with Visibility field.
+//
+// It returns *EventValidationError listing all invalid fields.
+func ValidateAgentEvent(e interface{}) error {
+	return validateEventFields(e, "visibility", "recipients")
+}
+
+// ValidateCustomerEvent checks fields of event sent with Customer Chat API. Visibility of the event is set
+// with Recipients field.
+//
+// It returns *EventValidationError listing all invalid fields.
+func ValidateCustomerEvent(e interface{}) error {
+	return validateEventFields(e, "recipients", "visibility")
+}
+
+type eventFieldsValidator struct {
+	eventType string
+	fields    []EventFieldError
+}
+
+func (v *eventFieldsValidator) fail(field, reason string, args ...interface{}) {
+	v.fields = append(v.fields, EventFieldError{Field: field, Reason: fmt.Sprintf(reason, args...)})
+}
+
+func validateEventFields(e interface{}, visibilityField, unsupportedVisibilityField string) error {
+	if err := ValidateEvent(e); err != nil {
+		return err
+	}
+
+	base, expectedType := baseEvent(e)
+	v := &eventFieldsValidator{eventType: base.Type}
+	if expectedType != "" && base.Type != expectedType {
+		v.fail("type", "expected %q, got %q", expectedType, base.Type)
+		v.eventType = expectedType
+	}
+	if v.eventType == "" {
+		v.eventType = "generic"
+	}
+
+	visibility := map[string]string{"visibility": base.Visibility, "recipients": base.Recipients}
+	switch visibility[visibilityField] {
+	case "", "all", "agents":
+	default:
+		v.fail(visibilityField, "expected \"all\" or \"agents\", got %q", visibility[visibilityField])
+	}
+	if visibility[unsupportedVisibilityField] != "" {
+		v.fail(unsupportedVisibilityField, "not supported, use %v instead", visibilityField)
+	}
+
+	switch ev := e.(type) {
+	case *Message:
+		v.validateMessage(ev)
+	case Message:
+		v.validateMessage(&ev)
+	case *SystemMessage:
+		v.validateSystemMessage(ev)
+	case SystemMessage:
+		v.validateSystemMessage(&ev)
+	case *File:
+		v.validateFile(ev)
+	case File:
+		v.validateFile(&ev)
+	case *RichMessage:
+		v.validateRichMessage(ev)
+	case RichMessage:
+		v.validateRichMessage(&ev)
+	case *CustomEvent:
+		v.validateCustomEvent(ev)
+	case CustomEvent:
+		v.validateCustomEvent(&ev)
+	case *Form:
+		v.validateForm(ev)
+	case Form:
+		v.validateForm(&ev)
+	}
+
+	if len(v.fields) > 0 {
+		return &EventValidationError{EventType: v.eventType, Fields: v.fields}
+	}
+	return nil
+}
+
+// baseEvent returns Event embedded in given event and type expected for its structure.
+func baseEvent(e interface{}) (*Event, string) {
+	switch ev := e.(type) {
+	case *Event:
+		return ev, ""
+	case Event:
+		return &ev, ""
+	case *Message:
+		return &ev.Event, "message"
+	case Message:
+		return &ev.Event, "message"
+	case *SystemMessage:
+		return &ev.Event, "system_message"
+	case SystemMessage:
+		return &ev.Event, "system_message"
+	case *File:
+		return &ev.Event, "file"
+	case File:
+		return &ev.Event, "file"
+	case *RichMessage:
+		return &ev.Event, "rich_message"
+	case RichMessage:
+		return &ev.Event, "rich_message"
+	case *CustomEvent:
+		return &ev.Event, "custom"
+	case CustomEvent:
+		return &ev.Event, "custom"
+	case *Form:
+		return &ev.Event, "form"
+	case Form:
+		return &ev.Event, "form"
+	}
+	return &Event{}, ""
+}
+
+func (v *eventFieldsValidator) validateMessage(m *Message) {
+	if strings.TrimSpace(m.Text) == "" {
+		v.fail("text", "empty")
+	}
+	if len(m.Text) > MaxTextSize {
+		v.fail("text", "exceeds %v bytes", MaxTextSize)
+	}
+}
+
+func (v *eventFieldsValidator) validateSystemMessage(sm *SystemMessage) {
+	if sm.SystemMessageType == "" {
+		v.fail("system_message_type", "empty")
+	}
+}
+
+func (v *eventFieldsValidator) validateFile(f *File) {
+	if err := validateAbsoluteURL(f.URL); err != nil {
+		v.fail("url", "%v", err)
+	}
+	if f.Width < 0 || f.Height < 0 || f.Size < 0 {
+		v.fail("size", "width, height and size can't be negative")
+	}
+}
+
+func (v *eventFieldsValidator) validateRichMessage(rm *RichMessage) {
+	if rm.TemplateID == "" {
+		v.fail("template_id", "empty")
+		return
+	}
+	if err := ValidateRichMessage(rm); err != nil {
+		v.fail("elements", "%v", err)
+	}
+}
+
+func (v *eventFieldsValidator) validateCustomEvent(c *CustomEvent) {
+	var content map[string]interface{}
+	if len(c.Content) == 0 {
+		v.fail("content", "empty")
+	} else if err := json.Unmarshal(c.Content, &content); err != nil {
+		v.fail("content", "must be JSON object: %v", err)
+	}
+}
+
+func (v *eventFieldsValidator) validateForm(f *Form) {
+	if f.FormID == "" {
+		v.fail("form_id", "empty")
+	}
+	if len(f.Fields) == 0 {
+		v.fail("fields", "empty")
+	}
+	for i, field := range f.Fields {
+		if field.ID == "" || field.Type == "" {
+			v.fail(fmt.Sprintf("fields[%v]", i), "missing id or type")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package objects_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/objects"
+)
+
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *objects.EventValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("invalid error type: %v", err)
+	}
+	var fields []string
+	for _, f := range validationErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	return fields
+}
+
+func TestValidateEventFields(t *testing.T) {
+	cases := []struct {
+		event    interface{}
+		customer bool
+		invalid  string
+	}{
+		{&objects.Event{}, false, ""},
+		{&objects.Message{Event: objects.Event{Type: "message", Visibility: "all"}, Text: "Hello"}, false, ""},
+		{objects.Message{Event: objects.Event{Type: "message", Recipients: "agents"}, Text: "Hello"}, true, ""},
+		{&objects.Message{Event: objects.Event{Type: "message"}, Text: ""}, false, "text"},
+		{&objects.Message{Event: objects.Event{Type: "message"}, Text: strings.Repeat("a", objects.MaxTextSize)}, false, ""},
+		{&objects.Message{Event: objects.Event{Type: "message"}, Text: strings.Repeat("ą", objects.MaxTextSize/2+1)}, false, "text"},
+		{&objects.Message{Event: objects.Event{Type: "file"}, Text: "Hello"}, false, "type"},
+		{&objects.Message{Event: objects.Event{Type: "message", Visibility: "customers"}, Text: "Hello"}, false, "visibility"},
+		{&objects.Message{Event: objects.Event{Type: "message", Visibility: "all"}, Text: "Hello"}, true, "visibility"},
+		{&objects.SystemMessage{Event: objects.Event{Type: "system_message"}}, false, "system_message_type"},
+		{&objects.File{Event: objects.Event{Type: "file"}, Name: "image.png"}, false, "url"},
+		{&objects.File{Event: objects.Event{Type: "file"}, URL: "https://cdn.example.com/image.png"}, false, ""},
+		{&objects.RichMessage{Event: objects.Event{Type: "rich_message"}}, false, "template_id"},
+		{&objects.RichMessage{Event: objects.Event{Type: "rich_message"}, TemplateID: objects.TemplateSticker}, false, "elements"},
+		{&objects.CustomEvent{Event: objects.Event{Type: "custom"}, Content: json.RawMessage(`[1, 2]`)}, false, "content"},
+		{&objects.CustomEvent{Event: objects.Event{Type: "custom"}, Content: json.RawMessage(`{"a": 1}`)}, false, ""},
+		{&objects.Form{Event: objects.Event{Type: "form"}}, false, "form_id,fields"},
+	}
+	for i, c := range cases {
+		validate := objects.ValidateAgentEvent
+		if c.customer {
+			validate = objects.ValidateCustomerEvent
+		}
+		if fields := strings.Join(invalidFields(t, validate(c.event)), ","); fields != c.invalid {
+			t.Errorf("case %v: invalid fields: %q, expected: %q", i, fields, c.invalid)
+		}
+	}
+
+	if err := objects.ValidateAgentEvent("message"); err == nil {
+		t.Error("unsupported event type should be reported")
+	}
+}
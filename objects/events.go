@@ -0,0 +1,447 @@
+package objects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/internal"
+)
+
+// ValidateEvent checks if given interface resolves into supported event type
+func ValidateEvent(e interface{}) error {
+	switch v := e.(type) {
+	case *Event:
+	case *File:
+	case *Message:
+	case *RichMessage:
+	case *SystemMessage:
+	case Event:
+	case File:
+	case Message:
+	case RichMessage:
+	case SystemMessage:
+	case *CustomEvent:
+	case CustomEvent:
+	case *Form:
+	case Form:
+	default:
+		return fmt.Errorf("event type %T not supported", v)
+	}
+
+	return nil
+}
+
+type eventSpecific struct {
+	Text              json.RawMessage `json:"text"`
+	TextVars          json.RawMessage `json:"text_vars"`
+	Fields            json.RawMessage `json:"fields"`
+	ContentType       json.RawMessage `json:"content_type"`
+	Name              json.RawMessage `json:"name"`
+	URL               json.RawMessage `json:"url"`
+	ThumbnailURL      json.RawMessage `json:"thumbnail_url"`
+	Thumbnail2xURL    json.RawMessage `json:"thumbnail2x_url"`
+	Width             json.RawMessage `json:"width"`
+	Height            json.RawMessage `json:"height"`
+	Size              json.RawMessage `json:"size"`
+	TemplateID        json.RawMessage `json:"template_id"`
+	Elements          json.RawMessage `json:"elements"`
+	Postback          json.RawMessage `json:"postback"`
+	AlternativeText   json.RawMessage `json:"alternative_text"`
+	SystemMessageType json.RawMessage `json:"system_message_type"`
+	Content           json.RawMessage `json:"content"`
+	FormID            json.RawMessage `json:"form_id"`
+}
+
+// Event represents base of all LiveChat chat events.
+//
+// To get specific event type's structure, call appropriate function based on Event's Type.
+//
+// Visibility is used by Agent Chat API and webhooks, while Recipients is used by Customer Chat API.
+type Event struct {
+	ID         string     `json:"id,omitempty"`
+	CustomID   string     `json:"custom_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AuthorID   string     `json:"author_id"`
+	Properties Properties `json:"properties,omitempty"`
+	Visibility string     `json:"visibility,omitempty"`
+	Recipients string     `json:"recipients,omitempty"`
+	Type       string     `json:"type,omitempty"`
+	eventSpecific
+}
+
+// ErrUnsupportedEventType is returned by Event's Decode function for events of types without dedicated structure.
+var ErrUnsupportedEventType = errors.New("unsupported event type")
+
+// TypedEvent is implemented by structures of specific event types: *Message, *SystemMessage, *File, *RichMessage,
+// *FilledForm, *Form and *CustomEvent. The interface is sealed, so it's meant to be used in type switch over result of Event's Decode function.
+type TypedEvent interface {
+	typedEvent()
+}
+
+func (*Message) typedEvent()       {}
+func (*SystemMessage) typedEvent() {}
+func (*File) typedEvent()          {}
+func (*RichMessage) typedEvent()   {}
+func (*FilledForm) typedEvent()    {}
+func (*Form) typedEvent()          {}
+func (*CustomEvent) typedEvent()   {}
+
+// Decode converts Event object to structure of its specific type, based on Event's Type.
+//
+// Unlike functions converting Event to particular types, it returns an error describing malformed field
+// if Event can't be decoded. For types without dedicated structure, error wrapping ErrUnsupportedEventType is returned.
+func (e *Event) Decode() (TypedEvent, error) {
+	var (
+		te  TypedEvent
+		err error
+	)
+	switch e.Type {
+	case "message":
+		te, err = e.decodeMessage()
+	case "system_message":
+		te, err = e.decodeSystemMessage()
+	case "file":
+		te, err = e.decodeFile()
+	case "rich_message":
+		te, err = e.decodeRichMessage()
+	case "filled_form":
+		te, err = e.decodeFilledForm()
+	case "form":
+		te, err = e.decodeForm()
+	case "custom":
+		te, err = e.decodeCustomEvent()
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedEventType, e.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode %v event %q: %v", e.Type, e.ID, err)
+	}
+	return te, nil
+}
+
+func decodeRequiredField(name string, source json.RawMessage, target interface{}) error {
+	if source == nil {
+		return fmt.Errorf("missing %v", name)
+	}
+	if err := json.Unmarshal(source, target); err != nil {
+		return fmt.Errorf("invalid %v: %v", name, err)
+	}
+	return nil
+}
+
+func decodeOptionalField(name string, source json.RawMessage, target interface{}) error {
+	if err := internal.UnmarshalOptionalRawField(source, target); err != nil {
+		return fmt.Errorf("invalid %v: %v", name, err)
+	}
+	return nil
+}
+
+// FilledForm represents LiveChat filled form event.
+type FilledForm struct {
+	Fields []struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"fields"`
+	Event
+}
+
+// FilledForm function converts Event object to FilledForm object if Event's Type is "filled_form".
+// If Type is different or Event is malformed, then it returns nil.
+func (e *Event) FilledForm() *FilledForm {
+	if e.Type != "filled_form" {
+		return nil
+	}
+	v, err := e.decodeFilledForm()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (e *Event) decodeFilledForm() (*FilledForm, error) {
+	f := FilledForm{Event: *e}
+	if err := decodeRequiredField("fields", e.Fields, &f.Fields); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Postback represents postback data in LiveChat message event.
+type Postback struct {
+	ID       string `json:"id"`
+	ThreadID string `json:"thread_id"`
+	EventID  string `json:"event_id"`
+	Type     string `json:"type,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// Message represents LiveChat message event.
+type Message struct {
+	Event
+	Text     string    `json:"text"`
+	Postback *Postback `json:"postback,omitempty"`
+}
+
+// Message function converts Event object to Message object if Event's Type is "message".
+// If Type is different or Event is malformed, then it returns nil.
+func (e *Event) Message() *Message {
+	if e.Type != "message" {
+		return nil
+	}
+	v, err := e.decodeMessage()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (e *Event) decodeMessage() (*Message, error) {
+	m := Message{Event: *e}
+	if err := decodeRequiredField("text", e.Text, &m.Text); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("postback", e.Postback, &m.Postback); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SystemMessage represents LiveChat system message event.
+type SystemMessage struct {
+	Event
+	SystemMessageType string            `json:"system_message_type"`
+	Text              string            `json:"text,omitempty"`
+	TextVars          map[string]string `json:"text_vars,omitempty"`
+}
+
+// SystemMessage function converts Event object to SystemMessage object if Event's Type is "system_message".
+// If Type is different or Event is malformed, then it returns nil.
+func (e *Event) SystemMessage() *SystemMessage {
+	if e.Type != "system_message" {
+		return nil
+	}
+	v, err := e.decodeSystemMessage()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (e *Event) decodeSystemMessage() (*SystemMessage, error) {
+	sm := SystemMessage{Event: *e}
+	if err := decodeRequiredField("system_message_type", e.SystemMessageType, &sm.SystemMessageType); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("text", e.Text, &sm.Text); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("text_vars", e.TextVars, &sm.TextVars); err != nil {
+		return nil, err
+	}
+	return &sm, nil
+}
+
+// File represents LiveChat file event
+type File struct {
+	Event
+	ContentType     string `json:"content_type"`
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	Thumbnail2xURL  string `json:"thumbnail2x_url,omitempty"`
+	Width           int    `json:"width,omitempty"`
+	Height          int    `json:"height,omitempty"`
+	Size            int    `json:"size,omitempty"`
+	AlternativeText string `json:"alternative_text,omitempty"`
+}
+
+// File function converts Event object to File object if Event's Type is "file".
+// If Type is different or Event is malformed, then it returns nil.
+func (e *Event) File() *File {
+	if e.Type != "file" {
+		return nil
+	}
+	v, err := e.decodeFile()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (e *Event) decodeFile() (*File, error) {
+	f := File{Event: *e}
+	if err := decodeRequiredField("content_type", e.ContentType, &f.ContentType); err != nil {
+		return nil, err
+	}
+	if err := decodeRequiredField("name", e.Name, &f.Name); err != nil {
+		return nil, err
+	}
+	if err := decodeRequiredField("url", e.URL, &f.URL); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("thumbnail_url", e.ThumbnailURL, &f.ThumbnailURL); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("thumbnail2x_url", e.Thumbnail2xURL, &f.Thumbnail2xURL); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("width", e.Width, &f.Width); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("height", e.Height, &f.Height); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("size", e.Size, &f.Size); err != nil {
+		return nil, err
+	}
+	if err := decodeOptionalField("alternative_text", e.AlternativeText, &f.AlternativeText); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// RichMessage represents LiveChat rich message event
+type RichMessage struct {
+	Event
+	TemplateID string               `json:"template_id"`
+	Elements   []RichMessageElement `json:"elements"`
+}
+
+// RichMessageElement represents element of LiveChat rich message
+type RichMessageElement struct {
+	Buttons  []RichMessageButton `json:"buttons"`
+	Title    string              `json:"title"`
+	Subtitle string              `json:"subtitle"`
+	Image    *RichMessageImage   `json:"image,omitempty"`
+}
+
+// RichMessageButton represents button in LiveChat rich message
+type RichMessageButton struct {
+	Text       string   `json:"text"`
+	Type       string   `json:"type"`
+	Value      string   `json:"value"`
+	UserIds    []string `json:"user_ids"`
+	PostbackID string   `json:"postback_id"`
+	// Allowed values: compact, full, tall
+	WebviewHeight string `json:"webview_height"`
+	// Allowed values: new, current
+	Target string `json:"target,omitempty"`
+}
+
+// RichMessageImage represents image in LiveChat rich message
+type RichMessageImage struct {
+	URL             string `json:"url"`
+	Name            string `json:"name,omitempty"`
+	ContentType     string `json:"content_type,omitempty"`
+	Size            int    `json:"size,omitempty"`
+	Width           int    `json:"width,omitempty"`
+	Height          int    `json:"height,omitempty"`
+	AlternativeText string `json:"alternative_text,omitempty"`
+}
+
+// RichMessage function converts Event object to RichMessage object if Event's Type is "rich_message".
+// If Type is different or Event is malformed, then it returns nil.
+func (e *Event) RichMessage() *RichMessage {
+	if e.Type != "rich_message" {
+		return nil
+	}
+	v, err := e.decodeRichMessage()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (e *Event) decodeRichMessage() (*RichMessage, error) {
+	rm := RichMessage{Event: *e}
+	if err := decodeRequiredField("template_id", e.TemplateID, &rm.TemplateID); err != nil {
+		return nil, err
+	}
+	if err := decodeRequiredField("elements", e.Elements, &rm.Elements); err != nil {
+		return nil, err
+	}
+	return &rm, nil
+}
+
+// FormField represents field of LiveChat form event.
+type FormField struct {
+	ID       string            `json:"id"`
+	Type     string            `json:"type"`
+	Label    string            `json:"label"`
+	Required bool              `json:"required,omitempty"`
+	Options  []FormFieldOption `json:"options,omitempty"`
+}
+
+// FormFieldOption represents option of LiveChat form field, e.g. of "select" or "checkbox" type.
+type FormFieldOption struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Checked bool   `json:"checked,omitempty"`
+}
+
+// Form represents LiveChat form event.
+type Form struct {
+	Event
+	FormID string      `json:"form_id"`
+	Fields []FormField `json:"fields"`
+}
+
+// Form function converts Event object to Form object if Event's Type is "form".
+// If Type is different or Event is malformed, then it returns nil.
+func (e *Event) Form() *Form {
+	if e.Type != "form" {
+		return nil
+	}
+	v, err := e.decodeForm()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (e *Event) decodeForm() (*Form, error) {
+	f := Form{Event: *e}
+	if err := decodeRequiredField("form_id", e.FormID, &f.FormID); err != nil {
+		return nil, err
+	}
+	if err := decodeRequiredField("fields", e.Fields, &f.Fields); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// CustomEvent represents LiveChat custom event, which carries arbitrary JSON object as its content.
+type CustomEvent struct {
+	Event
+	Content json.RawMessage `json:"content,omitempty"`
+}
+
+// UnmarshalContent decodes content of CustomEvent into given value.
+func (c *CustomEvent) UnmarshalContent(v interface{}) error {
+	return json.Unmarshal(c.Content, v)
+}
+
+// CustomEvent function converts Event object to CustomEvent object if Event's Type is "custom".
+// If Type is different or Event is malformed, then it returns nil.
+func (e *Event) CustomEvent() *CustomEvent {
+	if e.Type != "custom" {
+		return nil
+	}
+	v, err := e.decodeCustomEvent()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (e *Event) decodeCustomEvent() (*CustomEvent, error) {
+	c := CustomEvent{Event: *e}
+	if err := decodeOptionalField("content", e.Content, &c.Content); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,75 @@
+package objects_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/objects"
+)
+
+func decodeEvent(t *testing.T, data string) (objects.TypedEvent, error) {
+	t.Helper()
+	var e objects.Event
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatalf("couldn't unmarshal event: %v", err)
+	}
+	return e.Decode()
+}
+
+func TestDecodeEventTypes(t *testing.T) {
+	events := map[string]string{
+		"message":        `{"id": "e1", "type": "message", "text": "hello", "postback": {"id": "p1"}}`,
+		"system_message": `{"id": "e2", "type": "system_message", "system_message_type": "routing.assigned", "text_vars": {"agent": "John"}}`,
+		"file":           `{"id": "e3", "type": "file", "content_type": "image/png", "name": "image.png", "url": "https://cdn.example.com/image.png", "width": 640}`,
+		"rich_message":   `{"id": "e4", "type": "rich_message", "template_id": "cards", "elements": [{"title": "Card"}]}`,
+		"filled_form":    `{"id": "e5", "type": "filled_form", "fields": [{"id": "name", "value": "John"}]}`,
+		"form":           `{"id": "e6", "type": "form", "form_id": "f1", "fields": [{"id": "name", "type": "name", "label": "Name"}]}`,
+		"custom":         `{"id": "e7", "type": "custom", "content": {"order_id": "1234"}}`,
+	}
+	for eventType, data := range events {
+		te, err := decodeEvent(t, data)
+		if err != nil {
+			t.Errorf("couldn't decode %v: %v", eventType, err)
+			continue
+		}
+		var valid bool
+		switch v := te.(type) {
+		case *objects.Message:
+			valid = eventType == "message" && v.Text == "hello" && v.Postback.ID == "p1"
+		case *objects.SystemMessage:
+			valid = eventType == "system_message" && v.TextVars["agent"] == "John"
+		case *objects.File:
+			valid = eventType == "file" && v.Width == 640 && v.ID == "e3"
+		case *objects.RichMessage:
+			valid = eventType == "rich_message" && v.Elements[0].Title == "Card"
+		case *objects.FilledForm:
+			valid = eventType == "filled_form" && v.Fields[0].Value == "John"
+		case *objects.Form:
+			valid = eventType == "form" && v.FormID == "f1" && v.Fields[0].Label == "Name"
+		case *objects.CustomEvent:
+			valid = eventType == "custom" && string(v.Content) == `{"order_id": "1234"}`
+		}
+		if !valid {
+			t.Errorf("invalid %v decoded: %+v", eventType, te)
+		}
+	}
+}
+
+func TestDecodeMalformedEvent(t *testing.T) {
+	te, err := decodeEvent(t, `{"id": "e1", "type": "message", "text": 5}`)
+	if te != nil || err == nil || !strings.Contains(err.Error(), "invalid text") {
+		t.Errorf("malformed message should be reported: %v, %v", te, err)
+	}
+
+	te, err = decodeEvent(t, `{"id": "e2", "type": "file", "content_type": "image/png", "name": "image.png"}`)
+	if te != nil || err == nil || !strings.Contains(err.Error(), "missing url") {
+		t.Errorf("file without url should be reported: %v, %v", te, err)
+	}
+
+	_, err = decodeEvent(t, `{"id": "e3", "type": "unknown"}`)
+	if !errors.Is(err, objects.ErrUnsupportedEventType) {
+		t.Errorf("unsupported type should be reported: %v", err)
+	}
+}
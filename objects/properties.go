@@ -0,0 +1,315 @@
+package objects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// PropertyTag is the struct tag key binding struct fields to properties, in form of `lc:"namespace.name"`.
+//
+// Tag can be followed by options: "omitempty" skips zero value in MarshalProperties, and "required"
+// makes UnmarshalProperties fail if the property is missing.
+const PropertyTag = "lc"
+
+// MarshalProperties converts struct (or pointer to struct) with fields tagged with PropertyTag to Properties.
+// Nil pointer fields, including embedded pointers to structs, are skipped.
+//
+// Supported field types are strings, booleans, integers, floats, pointers to them and interface{}.
+func MarshalProperties(v interface{}) (Properties, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("couldn't marshal properties: nil value")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("couldn't marshal properties: expected struct, got %v", rv.Type())
+	}
+
+	props := make(Properties)
+	err := walkPropertyFields(rv, nil, func(f propertyField, fv reflect.Value) error {
+		if fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				return nil
+			}
+		}
+		if f.omitEmpty && fv.IsZero() {
+			return nil
+		}
+		value, err := marshalPropertyValue(fv)
+		if err != nil {
+			return err
+		}
+		if props[f.namespace] == nil {
+			props[f.namespace] = make(map[string]interface{})
+		}
+		props[f.namespace][f.name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal properties: %v", err)
+	}
+	return props, nil
+}
+
+// UnmarshalProperties sets fields of struct pointed by v, tagged with PropertyTag, to values of Properties.
+// Fields of missing properties are left intact.
+//
+// Numbers are converted to the field's type as long as they fit in it without loss, e.g. float64 decoded
+// from JSON can be set to int field if it has no fractional part. Nil embedded pointers to structs are
+// allocated only if any of their properties is present.
+func UnmarshalProperties(p Properties, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("couldn't unmarshal properties: expected non-nil pointer to struct, got %T", v)
+	}
+
+	present := func(f propertyField) bool {
+		_, exists := p[f.namespace][f.name]
+		return exists
+	}
+	err := walkPropertyFields(rv.Elem(), present, func(f propertyField, fv reflect.Value) error {
+		value, exists := p[f.namespace][f.name]
+		if !exists {
+			if f.required {
+				return errors.New("missing required property")
+			}
+			return nil
+		}
+		return unmarshalPropertyValue(value, fv)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't unmarshal properties: %v", err)
+	}
+	return nil
+}
+
+type propertyField struct {
+	namespace string
+	name      string
+	omitEmpty bool
+	required  bool
+}
+
+func parsePropertyTag(tag string) (propertyField, error) {
+	parts := strings.Split(tag, ",")
+	var f propertyField
+	i := strings.Index(parts[0], ".")
+	if i <= 0 || i == len(parts[0])-1 {
+		return f, fmt.Errorf("invalid %v tag %q, expected \"namespace.name\"", PropertyTag, tag)
+	}
+	f.namespace, f.name = parts[0][:i], parts[0][i+1:]
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			f.omitEmpty = true
+		case "required":
+			f.required = true
+		default:
+			return f, fmt.Errorf("invalid %v tag %q, unknown option %q", PropertyTag, tag, opt)
+		}
+	}
+	return f, nil
+}
+
+// walkPropertyFields calls fn for each tagged field of the struct, including fields of embedded structs
+// and embedded pointers to structs.
+//
+// Nil embedded pointers are skipped if present is nil. Otherwise they're allocated if present reports
+// any of their fields, so that fn can set it.
+func walkPropertyFields(rv reflect.Value, present func(f propertyField) bool, fn func(f propertyField, fv reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, tagged := sf.Tag.Lookup(PropertyTag)
+		if !tagged {
+			if !sf.Anonymous {
+				continue
+			}
+			ev := rv.Field(i)
+			switch {
+			case sf.Type.Kind() == reflect.Struct:
+			case sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct:
+				if ev.IsNil() {
+					if present == nil || !containsPropertyField(sf.Type.Elem(), present) {
+						continue
+					}
+					if sf.PkgPath != "" {
+						return fmt.Errorf("field %v: can't allocate embedded pointer to unexported struct", sf.Name)
+					}
+					ev.Set(reflect.New(sf.Type.Elem()))
+				}
+				ev = ev.Elem()
+			default:
+				continue
+			}
+			if err := walkPropertyFields(ev, present, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return fmt.Errorf("field %v: tagged field must be exported", sf.Name)
+		}
+		f, err := parsePropertyTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %v: %v", sf.Name, err)
+		}
+		if err := fn(f, rv.Field(i)); err != nil {
+			return fmt.Errorf("property %v.%v (field %v): %v", f.namespace, f.name, sf.Name, err)
+		}
+	}
+	return nil
+}
+
+// containsPropertyField reports whether struct of given type has tagged field reported by present.
+func containsPropertyField(rt reflect.Type, present func(f propertyField) bool) bool {
+	found := false
+	walkPropertyFields(reflect.New(rt).Elem(), present, func(f propertyField, fv reflect.Value) error {
+		if present(f) {
+			found = true
+			return errors.New("property field found")
+		}
+		return nil
+	})
+	return found
+}
+
+func marshalPropertyValue(fv reflect.Value) (interface{}, error) {
+	for fv.Kind() == reflect.Ptr {
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return fv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), nil
+	case reflect.Interface:
+		return fv.Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %v", fv.Type())
+}
+
+func unmarshalPropertyValue(value interface{}, fv reflect.Value) error {
+	if fv.Kind() == reflect.Interface {
+		if value == nil {
+			fv.Set(reflect.Zero(fv.Type()))
+			return nil
+		}
+		src := reflect.ValueOf(value)
+		if !src.Type().AssignableTo(fv.Type()) {
+			return fmt.Errorf("can't use %T value as %v", value, fv.Type())
+		}
+		fv.Set(src)
+		return nil
+	}
+	if value == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	if fv.Kind() == reflect.Ptr {
+		elem := reflect.New(fv.Type().Elem())
+		if err := unmarshalPropertyValue(value, elem.Elem()); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+
+	if num, ok := value.(json.Number); ok {
+		if n, err := num.Int64(); err == nil {
+			value = n
+		} else if f, err := num.Float64(); err == nil {
+			value = f
+		}
+	}
+	src := reflect.ValueOf(value)
+	switch fv.Kind() {
+	case reflect.String:
+		if src.Kind() != reflect.String {
+			return fmt.Errorf("can't use %T value as %v", value, fv.Type())
+		}
+		fv.SetString(src.String())
+	case reflect.Bool:
+		if src.Kind() != reflect.Bool {
+			return fmt.Errorf("can't use %T value as %v", value, fv.Type())
+		}
+		fv.SetBool(src.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := intValue(src)
+		if !ok || fv.OverflowInt(n) {
+			return fmt.Errorf("can't use %v (%T) as %v", value, value, fv.Type())
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := uintValue(src)
+		if !ok || fv.OverflowUint(n) {
+			return fmt.Errorf("can't use %v (%T) as %v", value, value, fv.Type())
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, ok := numberValue(src)
+		if !ok || fv.OverflowFloat(n) {
+			return fmt.Errorf("can't use %v (%T) as %v", value, value, fv.Type())
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %v", fv.Type())
+	}
+	return nil
+}
+
+// numberValue returns value of numeric kind as float64.
+func numberValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// intValue returns value of numeric kind as int64, if it's integral and fits in int64.
+func intValue(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), v.Uint() <= math.MaxInt64
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return int64(f), f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+	}
+	return 0, false
+}
+
+// uintValue returns value of numeric kind as uint64, if it's integral, non-negative and fits in uint64.
+func uintValue(v reflect.Value) (uint64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()), v.Int() >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return uint64(f), f == math.Trunc(f) && f >= 0 && f < math.MaxUint64
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package objects_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/objects"
+)
+
+type Status string
+
+type Routing struct {
+	Pinned bool `lc:"routing.pinned"`
+}
+
+type integration struct {
+	Routing
+	OrderID  string  `lc:"shop.order_id,required"`
+	Status   Status  `lc:"shop.status,omitempty"`
+	Items    int     `lc:"shop.items"`
+	Total    float64 `lc:"shop.total"`
+	Priority *uint8  `lc:"shop.priority"`
+	Ignored  string  `lc:"-"`
+}
+
+func TestPropertiesRoundTrip(t *testing.T) {
+	priority := uint8(3)
+	in := integration{
+		Routing:  Routing{Pinned: true},
+		OrderID:  "1234",
+		Items:    2,
+		Total:    10.5,
+		Priority: &priority,
+		Ignored:  "ignored",
+	}
+	props, err := objects.MarshalProperties(&in)
+	if err != nil {
+		t.Fatalf("MarshalProperties failed: %v", err)
+	}
+	if _, exists := props["shop"]["status"]; exists {
+		t.Error("empty status should be omitted")
+	}
+	if len(props["shop"]) != 4 || props["routing"]["pinned"] != true {
+		t.Errorf("invalid properties: %v", props)
+	}
+
+	// Properties sent to the API come back as decoded JSON, with numbers as float64.
+	data, _ := json.Marshal(props)
+	var decoded objects.Properties
+	json.Unmarshal(data, &decoded)
+
+	var out integration
+	if err := objects.UnmarshalProperties(decoded, &out); err != nil {
+		t.Fatalf("UnmarshalProperties failed: %v", err)
+	}
+	in.Ignored = ""
+	if out.OrderID != in.OrderID || out.Items != in.Items || out.Total != in.Total || *out.Priority != priority || !out.Pinned {
+		t.Errorf("invalid struct: %+v, expected: %+v", out, in)
+	}
+}
+
+func TestUnmarshalPropertiesValidation(t *testing.T) {
+	cases := map[string]objects.Properties{
+		"missing required property":                      {"shop": {"items": 1}},
+		"can't use 1 (string) as int":                    {"shop": {"order_id": "1", "items": "1"}},
+		"can't use 1.5 (float64) as int":                 {"shop": {"order_id": "1", "items": 1.5}},
+		"can't use 300 (float64) as uint8":               {"shop": {"order_id": "1", "priority": 300.0}},
+		"can't use float64 value as objects_test.Status": {"shop": {"order_id": "1", "status": 1.0}},
+	}
+	for expected, props := range cases {
+		var out integration
+		if err := objects.UnmarshalProperties(props, &out); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("invalid error, expected %q: %v", expected, err)
+		}
+	}
+
+	var invalidTag struct {
+		Field string `lc:"field"`
+	}
+	if err := objects.UnmarshalProperties(objects.Properties{}, &invalidTag); err == nil {
+		t.Error("invalid tag should be reported")
+	}
+	if err := objects.UnmarshalProperties(objects.Properties{}, invalidTag); err == nil {
+		t.Error("non-pointer value should be reported")
+	}
+}
+
+type Tracking struct {
+	Source string `lc:"tracking.source"`
+}
+
+type campaign struct {
+	*Routing
+	*Tracking
+	Name string `lc:"shop.campaign"`
+}
+
+func TestPropertiesOfEmbeddedPointers(t *testing.T) {
+	in := campaign{Routing: &Routing{Pinned: true}, Name: "spring"}
+	props, err := objects.MarshalProperties(in)
+	if err != nil {
+		t.Fatalf("MarshalProperties failed: %v", err)
+	}
+	if len(props) != 2 || props["routing"]["pinned"] != true || props["shop"]["campaign"] != "spring" {
+		t.Errorf("invalid properties: %v", props)
+	}
+
+	var out campaign
+	if err := objects.UnmarshalProperties(props, &out); err != nil {
+		t.Fatalf("UnmarshalProperties failed: %v", err)
+	}
+	if out.Routing == nil || !out.Pinned || out.Name != "spring" {
+		t.Errorf("invalid struct: %+v", out)
+	}
+	if out.Tracking != nil {
+		t.Errorf("embedded pointer without properties shouldn't be allocated: %+v", out.Tracking)
+	}
+}
+
+func TestUnmarshalPropertiesToInterface(t *testing.T) {
+	var out struct {
+		Any      interface{}  `lc:"shop.any"`
+		Stringer fmt.Stringer `lc:"shop.stringer"`
+	}
+	if err := objects.UnmarshalProperties(objects.Properties{"shop": {"any": "value"}}, &out); err != nil || out.Any != "value" {
+		t.Errorf("UnmarshalProperties failed: %v, %+v", err, out)
+	}
+	err := objects.UnmarshalProperties(objects.Properties{"shop": {"stringer": "value"}}, &out)
+	if err == nil || !strings.Contains(err.Error(), "can't use string value as fmt.Stringer") {
+		t.Errorf("invalid error: %v", err)
+	}
+}
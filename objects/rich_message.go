@@ -0,0 +1,237 @@
+package objects
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Rich message templates supported by RichMessageBuilder.
+const (
+	TemplateCards        = "cards"
+	TemplateQuickReplies = "quick_replies"
+	TemplateSticker      = "sticker"
+)
+
+// Types of rich message buttons.
+const (
+	ButtonTypeWebview = "webview"
+	ButtonTypeMessage = "message"
+	ButtonTypeURL     = "url"
+	ButtonTypePhone   = "phone"
+	ButtonTypeCancel  = "cancel"
+)
+
+// Heights of webview opened by rich message button of "webview" type.
+const (
+	WebviewHeightCompact = "compact"
+	WebviewHeightFull    = "full"
+	WebviewHeightTall    = "tall"
+)
+
+// Targets of link opened by rich message button of "url" type.
+const (
+	ButtonTargetNew     = "new"
+	ButtonTargetCurrent = "current"
+)
+
+// RichMessageBuilder allows to build RichMessage of cards, quick_replies or sticker template.
+//
+// Elements are added with WithElement; WithImage and WithButton apply to the most recently added element.
+// Built message is validated with ValidateRichMessage.
+type RichMessageBuilder struct {
+	templateID string
+	elements   []RichMessageElement
+	err        error
+}
+
+// NewRichMessageBuilder creates RichMessageBuilder for given template.
+func NewRichMessageBuilder(templateID string) *RichMessageBuilder {
+	return &RichMessageBuilder{templateID: templateID}
+}
+
+// WithElement allows to add element with given title and subtitle, which can be empty.
+func (b *RichMessageBuilder) WithElement(title, subtitle string) *RichMessageBuilder {
+	b.elements = append(b.elements, RichMessageElement{
+		Title:    title,
+		Subtitle: subtitle,
+	})
+	return b
+}
+
+// WithImage allows to set image of the last added element.
+func (b *RichMessageBuilder) WithImage(image RichMessageImage) *RichMessageBuilder {
+	el := b.lastElement("image")
+	if el != nil {
+		el.Image = &image
+	}
+	return b
+}
+
+// WithButton allows to add button to the last added element.
+func (b *RichMessageBuilder) WithButton(button RichMessageButton) *RichMessageBuilder {
+	el := b.lastElement("button")
+	if el != nil {
+		el.Buttons = append(el.Buttons, button)
+	}
+	return b
+}
+
+func (b *RichMessageBuilder) lastElement(field string) *RichMessageElement {
+	if len(b.elements) == 0 {
+		if b.err == nil {
+			b.err = fmt.Errorf("%v added before any element", field)
+		}
+		return nil
+	}
+	return &b.elements[len(b.elements)-1]
+}
+
+// Build returns RichMessage event or error if the message is invalid.
+func (b *RichMessageBuilder) Build() (*RichMessage, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	rm := &RichMessage{
+		Event:      Event{Type: "rich_message"},
+		TemplateID: b.templateID,
+		Elements:   append([]RichMessageElement(nil), b.elements...),
+	}
+	if err := ValidateRichMessage(rm); err != nil {
+		return nil, err
+	}
+	return rm, nil
+}
+
+// ValidateRichMessage checks if RichMessage conforms to its template: number of elements and buttons,
+// button types and their options, postback IDs and image fields.
+//
+// Postback ID is required for buttons sending a postback when clicked, i.e. of "message", "webview"
+// and "cancel" type, and must be unique within the message.
+func ValidateRichMessage(rm *RichMessage) error {
+	// maxElements and maxButtons are negative if number of elements or buttons isn't limited.
+	var maxElements, maxButtons int
+	switch rm.TemplateID {
+	case TemplateCards:
+		maxElements, maxButtons = -1, -1
+	case TemplateQuickReplies:
+		maxElements, maxButtons = 1, -1
+	case TemplateSticker:
+		maxElements, maxButtons = 1, 0
+	default:
+		return fmt.Errorf("unsupported rich message template %q", rm.TemplateID)
+	}
+	if len(rm.Elements) == 0 {
+		return fmt.Errorf("%v rich message must have at least 1 element", rm.TemplateID)
+	}
+	if maxElements >= 0 && len(rm.Elements) > maxElements {
+		return fmt.Errorf("%v rich message must have from 1 to %v elements, got %v", rm.TemplateID, maxElements, len(rm.Elements))
+	}
+
+	postbackIDs := make(map[string]bool)
+	for i, el := range rm.Elements {
+		if maxButtons >= 0 && len(el.Buttons) > maxButtons {
+			return fmt.Errorf("element %v: %v rich message element can have at most %v buttons, got %v", i, rm.TemplateID, maxButtons, len(el.Buttons))
+		}
+		switch rm.TemplateID {
+		case TemplateCards:
+			if el.Title == "" && el.Subtitle == "" && el.Image == nil {
+				return fmt.Errorf("element %v: card must have title, subtitle or image", i)
+			}
+		case TemplateQuickReplies:
+			if len(el.Buttons) == 0 {
+				return fmt.Errorf("element %v: quick replies must have at least one button", i)
+			}
+		case TemplateSticker:
+			if el.Image == nil {
+				return fmt.Errorf("element %v: sticker must have image", i)
+			}
+		}
+		if el.Image != nil {
+			if err := validateRichMessageImage(el.Image); err != nil {
+				return fmt.Errorf("element %v: invalid image: %v", i, err)
+			}
+		}
+		for j, button := range el.Buttons {
+			if err := validateRichMessageButton(&button); err != nil {
+				return fmt.Errorf("element %v, button %v: %v", i, j, err)
+			}
+			if button.PostbackID == "" {
+				continue
+			}
+			if postbackIDs[button.PostbackID] {
+				return fmt.Errorf("element %v, button %v: duplicated postback ID %q", i, j, button.PostbackID)
+			}
+			postbackIDs[button.PostbackID] = true
+		}
+	}
+	return nil
+}
+
+func validateRichMessageButton(button *RichMessageButton) error {
+	if button.Text == "" {
+		return errors.New("missing text")
+	}
+
+	switch button.Type {
+	case ButtonTypeWebview, ButtonTypeURL:
+		if err := validateAbsoluteURL(button.Value); err != nil {
+			return fmt.Errorf("invalid %v value: %v", button.Type, err)
+		}
+	case ButtonTypePhone:
+		if button.Value == "" {
+			return errors.New("missing phone number")
+		}
+	case ButtonTypeMessage, ButtonTypeCancel:
+	default:
+		return fmt.Errorf("unsupported type %q", button.Type)
+	}
+	if button.PostbackID == "" && button.Type != ButtonTypeURL && button.Type != ButtonTypePhone {
+		return fmt.Errorf("missing postback ID of %v button", button.Type)
+	}
+
+	switch button.WebviewHeight {
+	case "":
+	case WebviewHeightCompact, WebviewHeightFull, WebviewHeightTall:
+		if button.Type != ButtonTypeWebview {
+			return fmt.Errorf("webview height set for %v button", button.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported webview height %q", button.WebviewHeight)
+	}
+
+	switch button.Target {
+	case "":
+	case ButtonTargetNew, ButtonTargetCurrent:
+		if button.Type != ButtonTypeURL {
+			return fmt.Errorf("target set for %v button", button.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported target %q", button.Target)
+	}
+	return nil
+}
+
+func validateRichMessageImage(image *RichMessageImage) error {
+	if err := validateAbsoluteURL(image.URL); err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+	if image.Width < 0 || image.Height < 0 || image.Size < 0 {
+		return errors.New("width, height and size can't be negative")
+	}
+	return nil
+}
+
+func validateAbsoluteURL(value string) error {
+	if value == "" {
+		return errors.New("empty URL")
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q is not absolute http(s) URL", value)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package objects_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/objects"
+)
+
+var image = objects.RichMessageImage{URL: "https://cdn.example.com/image.png"}
+
+func button(buttonType, postbackID, value string) objects.RichMessageButton {
+	return objects.RichMessageButton{Text: "Button", Type: buttonType, PostbackID: postbackID, Value: value}
+}
+
+func TestRichMessageBuilderTemplates(t *testing.T) {
+	builders := map[string]*objects.RichMessageBuilder{
+		objects.TemplateCards: objects.NewRichMessageBuilder(objects.TemplateCards).
+			WithElement("First", "Card").
+			WithImage(image).
+			WithButton(button(objects.ButtonTypeURL, "open", "https://example.com")).
+			WithElement("Second", "").
+			WithButton(button(objects.ButtonTypeMessage, "yes", "")),
+		objects.TemplateQuickReplies: objects.NewRichMessageBuilder(objects.TemplateQuickReplies).
+			WithElement("Pick one", "").
+			WithButton(button(objects.ButtonTypeMessage, "a", "")).
+			WithButton(button(objects.ButtonTypeMessage, "b", "")),
+		objects.TemplateSticker: objects.NewRichMessageBuilder(objects.TemplateSticker).
+			WithElement("", "").
+			WithImage(image),
+	}
+	for template, b := range builders {
+		rm, err := b.Build()
+		if err != nil {
+			t.Errorf("couldn't build %v: %v", template, err)
+			continue
+		}
+		if rm.Type != "rich_message" || rm.TemplateID != template {
+			t.Errorf("invalid %v: %+v", template, rm)
+		}
+		if err := objects.ValidateEvent(rm); err != nil {
+			t.Errorf("built %v isn't valid event: %v", template, err)
+		}
+	}
+}
+
+func TestRichMessageBuilderValidation(t *testing.T) {
+	webview := button(objects.ButtonTypeWebview, "webview", "https://example.com")
+	webview.WebviewHeight = "huge"
+	target := button(objects.ButtonTypeMessage, "message", "")
+	target.Target = objects.ButtonTargetNew
+
+	cases := map[string]*objects.RichMessageBuilder{
+		"unsupported rich message template": objects.NewRichMessageBuilder("carousel").WithElement("Title", ""),
+		"at least 1 element":                objects.NewRichMessageBuilder(objects.TemplateCards),
+		"from 1 to 1 elements":              objects.NewRichMessageBuilder(objects.TemplateQuickReplies).WithElement("First", "").WithElement("Second", ""),
+		"at least one button":               objects.NewRichMessageBuilder(objects.TemplateQuickReplies).WithElement("Pick one", ""),
+		"sticker must have image":           objects.NewRichMessageBuilder(objects.TemplateSticker).WithElement("", ""),
+		"at most 0 buttons": objects.NewRichMessageBuilder(objects.TemplateSticker).WithElement("", "").WithImage(image).
+			WithButton(button(objects.ButtonTypeMessage, "a", "")),
+		"image added before any element": objects.NewRichMessageBuilder(objects.TemplateSticker).WithImage(image),
+		"invalid image":                  objects.NewRichMessageBuilder(objects.TemplateCards).WithElement("Title", "").WithImage(objects.RichMessageImage{URL: "image.png"}),
+		"unsupported type":               objects.NewRichMessageBuilder(objects.TemplateCards).WithElement("Title", "").WithButton(button("submit", "a", "")),
+		"missing postback ID":            objects.NewRichMessageBuilder(objects.TemplateCards).WithElement("Title", "").WithButton(button(objects.ButtonTypeMessage, "", "")),
+		"duplicated postback ID": objects.NewRichMessageBuilder(objects.TemplateCards).
+			WithElement("First", "").WithButton(button(objects.ButtonTypeMessage, "a", "")).
+			WithElement("Second", "").WithButton(button(objects.ButtonTypeMessage, "a", "")),
+		"invalid url value":             objects.NewRichMessageBuilder(objects.TemplateCards).WithElement("Title", "").WithButton(button(objects.ButtonTypeURL, "a", "example")),
+		"unsupported webview height":    objects.NewRichMessageBuilder(objects.TemplateCards).WithElement("Title", "").WithButton(webview),
+		"target set for message button": objects.NewRichMessageBuilder(objects.TemplateCards).WithElement("Title", "").WithButton(target),
+	}
+	for expected, b := range cases {
+		if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("invalid error, expected %q: %v", expected, err)
+		}
+	}
+}
+
+func TestRichMessageBuilderAllowsLinkButtonsWithoutPostbackID(t *testing.T) {
+	b := objects.NewRichMessageBuilder(objects.TemplateCards).
+		WithElement("Contact", "").
+		WithButton(button(objects.ButtonTypeURL, "", "https://example.com")).
+		WithButton(button(objects.ButtonTypePhone, "", "+48123456789")).
+		WithButton(button(objects.ButtonTypeMessage, "yes", ""))
+	if _, err := b.Build(); err != nil {
+		t.Errorf("Build failed: %v", err)
+	}
+
+	for _, buttonType := range []string{objects.ButtonTypeWebview, objects.ButtonTypeCancel} {
+		b := objects.NewRichMessageBuilder(objects.TemplateCards).
+			WithElement("Title", "").
+			WithButton(button(buttonType, "", "https://example.com"))
+		expected := "missing postback ID of " + buttonType + " button"
+		if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("invalid error, expected %q: %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package objects
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/internal"
+)
+
+// User represents base of both Customer and Agent
+//
+// To get specific user type's structure, call Agent() or Customer() (based on Type value).
+type User struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Name           string    `json:"name"`
+	Avatar         string    `json:"avatar"`
+	Email          string    `json:"email"`
+	Present        bool      `json:"present"`
+	EventsSeenUpTo time.Time `json:"events_seen_up_to"`
+	userSpecific
+}
+
+type userSpecific struct {
+	RoutingStatus              json.RawMessage `json:"routing_status"`
+	LastVisit                  json.RawMessage `json:"last_visit"`
+	Statistics                 json.RawMessage `json:"statistics"`
+	AgentLastEventCreatedAt    json.RawMessage `json:"agent_last_event_created_at"`
+	CustomerLastEventCreatedAt json.RawMessage `json:"customer_last_event_created_at"`
+	SessionFields              json.RawMessage `json:"session_fields"`
+	Followed                   json.RawMessage `json:"followed"`
+	Online                     json.RawMessage `json:"online"`
+	State                      json.RawMessage `json:"state"`
+	GroupIDs                   json.RawMessage `json:"group_ids"`
+	EmailVerified              json.RawMessage `json:"email_verified"`
+	CreatedAt                  json.RawMessage `json:"created_at"`
+	Visibility                 json.RawMessage `json:"visibility"`
+}
+
+// Agent function converts User object to Agent object if User's Type is "agent".
+// If Type is different or User is malformed, then it returns nil.
+func (u *User) Agent() *Agent {
+	if u.Type != "agent" {
+		return nil
+	}
+	var a Agent
+
+	a.User = u
+	if err := internal.UnmarshalOptionalRawField(u.RoutingStatus, &a.RoutingStatus); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.Visibility, &a.Visibility); err != nil {
+		return nil
+	}
+	return &a
+}
+
+// Customer function converts User object to Customer object if User's Type is "customer".
+// Optional fields that are missing in User (e.g. not returned by Customer Chat API) or set to null
+// are left empty, whereas earlier versions returned nil for such users.
+// If Type is different or User is malformed, then it returns nil.
+func (u *User) Customer() *Customer {
+	if u.Type != "customer" {
+		return nil
+	}
+	var c Customer
+
+	c.User = u
+	if err := internal.UnmarshalOptionalRawField(u.LastVisit, &c.LastVisit); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.Statistics, &c.Statistics); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.AgentLastEventCreatedAt, &c.AgentLastEventCreatedAt); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.CustomerLastEventCreatedAt, &c.CustomerLastEventCreatedAt); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.EmailVerified, &c.EmailVerified); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.CreatedAt, &c.CreatedAt); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.Followed, &c.Followed); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.Online, &c.Online); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.State, &c.State); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.SessionFields, &c.SessionFields); err != nil {
+		return nil
+	}
+	if err := internal.UnmarshalOptionalRawField(u.GroupIDs, &c.GroupIDs); err != nil {
+		return nil
+	}
+	return &c
+}
+
+// Visit contains information about particular customer's visit.
+type Visit struct {
+	IP          string      `json:"ip"`
+	UserAgent   string      `json:"user_agent"`
+	Geolocation Geolocation `json:"geolocation"`
+	StartedAt   time.Time   `json:"started_at"`
+	EndedAt     time.Time   `json:"ended_at"`
+	Referrer    string      `json:"referrer"`
+	LastPages   []struct {
+		OpenedAt time.Time `json:"opened_at"`
+		URL      string    `json:"url"`
+		Title    string    `json:"title"`
+	} `json:"last_pages"`
+}
+
+// Geolocation contains geolocation information.
+type Geolocation struct {
+	Country     string `json:"country"`
+	CountryCode string `json:"country_code"`
+	Region      string `json:"region"`
+	City        string `json:"city"`
+	Timezone    string `json:"timezone"`
+	Latitude    string `json:"latitude"`
+	Longitude   string `json:"longitude"`
+}
+
+// Agent represents LiveChat agent.
+type Agent struct {
+	*User
+	RoutingStatus string `json:"routing_status,omitempty"`
+	Visibility    string `json:"visibility,omitempty"`
+}
+
+// Customer represents LiveChat customer.
+type Customer struct {
+	*User
+	EmailVerified bool  `json:"email_verified"`
+	LastVisit     Visit `json:"last_visit"`
+	Statistics    struct {
+		VisitsCount            int `json:"visits_count"`
+		ThreadsCount           int `json:"threads_count"`
+		ChatsCount             int `json:"chats_count"`
+		PageViewsCount         int `json:"page_views_count"`
+		GreetingsShownCount    int `json:"greetings_shown_count"`
+		GreetingsAcceptedCount int `json:"greetings_accepted_count"`
+	} `json:"statistics"`
+	AgentLastEventCreatedAt    time.Time           `json:"agent_last_event_created_at"`
+	CustomerLastEventCreatedAt time.Time           `json:"customer_last_event_created_at"`
+	CreatedAt                  time.Time           `json:"created_at"`
+	SessionFields              []map[string]string `json:"session_fields"`
+	Followed                   bool                `json:"followed"`
+	Online                     bool                `json:"online"`
+	State                      string              `json:"state"`
+	GroupIDs                   []int               `json:"group_ids"`
+}
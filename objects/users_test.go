@@ -0,0 +1,59 @@
+package objects_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/objects"
+)
+
+func TestCustomerFromCustomerChatAPIUser(t *testing.T) {
+	data := `{
+		"id": "b7eff798-f8df-4364-8059-649c35c9ed0c",
+		"type": "customer",
+		"name": "Thomas Anderson",
+		"email": "t.anderson@example.com",
+		"email_verified": true,
+		"session_fields": [{"custom_key": "custom_value"}]
+	}`
+	var u objects.User
+	if err := json.Unmarshal([]byte(data), &u); err != nil {
+		t.Fatalf("couldn't unmarshal user: %v", err)
+	}
+
+	c := u.Customer()
+	if c == nil {
+		t.Fatal("customer should be decoded")
+	}
+	if c.Email != "t.anderson@example.com" || !c.EmailVerified || c.SessionFields[0]["custom_key"] != "custom_value" {
+		t.Errorf("invalid customer: %+v", c)
+	}
+	if u.Agent() != nil {
+		t.Error("customer shouldn't be decoded as agent")
+	}
+}
+
+func TestCustomerMalformed(t *testing.T) {
+	var u objects.User
+	if err := json.Unmarshal([]byte(`{"id": "c", "type": "customer", "email_verified": "yes"}`), &u); err != nil {
+		t.Fatalf("couldn't unmarshal user: %v", err)
+	}
+	if c := u.Customer(); c != nil {
+		t.Errorf("malformed customer shouldn't be decoded: %+v", c)
+	}
+}
+
+func TestCustomerWithNullOptionalFields(t *testing.T) {
+	var u objects.User
+	data := `{"id": "c", "type": "customer", "last_visit": null, "statistics": null, "created_at": null, "group_ids": null}`
+	if err := json.Unmarshal([]byte(data), &u); err != nil {
+		t.Fatalf("couldn't unmarshal user: %v", err)
+	}
+	c := u.Customer()
+	if c == nil {
+		t.Fatal("customer with null optional fields should be decoded")
+	}
+	if c.ID != "c" || !c.CreatedAt.IsZero() || c.GroupIDs != nil || c.Statistics.ChatsCount != 0 {
+		t.Errorf("invalid customer: %+v", c)
+	}
+}
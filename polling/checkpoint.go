@@ -0,0 +1,105 @@
+package polling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Checkpoint describes state of chats observed by Poller. It can be persisted with CheckpointStore,
+// so that polling is resumed where it stopped.
+//
+// Only chats which can still change are kept: chats with active thread and chats whose last thread is
+// the latest one observed. Chats with inactive thread created before ThreadsSince are pruned - their new
+// threads are detected by ThreadsSince.
+type Checkpoint struct {
+	Chats map[string]ChatCheckpoint `json:"chats"`
+	// ThreadsSince is creation time of the latest thread observed. Chats with threads created before it
+	// and not kept in Chats haven't changed.
+	ThreadsSince time.Time `json:"threads_since,omitempty"`
+}
+
+// ChatCheckpoint describes observed state of a single chat.
+type ChatCheckpoint struct {
+	ThreadID        string    `json:"thread_id"`
+	ThreadActive    bool      `json:"thread_active"`
+	ThreadCreatedAt time.Time `json:"thread_created_at,omitempty"`
+	LastEventID     string    `json:"last_event_id,omitempty"`
+	LastEventAt     time.Time `json:"last_event_at,omitempty"`
+}
+
+// prune advances ThreadsSince to the latest observed thread and removes chats which can't change anymore.
+func (c *Checkpoint) prune() {
+	for _, chat := range c.Chats {
+		if chat.ThreadCreatedAt.After(c.ThreadsSince) {
+			c.ThreadsSince = chat.ThreadCreatedAt
+		}
+	}
+	for id, chat := range c.Chats {
+		if !chat.ThreadActive && chat.ThreadCreatedAt.Before(c.ThreadsSince) {
+			delete(c.Chats, id)
+		}
+	}
+}
+
+func (c *Checkpoint) clone() *Checkpoint {
+	clone := &Checkpoint{Chats: make(map[string]ChatCheckpoint, len(c.Chats)), ThreadsSince: c.ThreadsSince}
+	for id, chat := range c.Chats {
+		clone.Chats[id] = chat
+	}
+	return clone
+}
+
+// CheckpointStore persists Poller's Checkpoint.
+type CheckpointStore interface {
+	// Load returns saved Checkpoint, or nil if there is none.
+	Load() (*Checkpoint, error)
+	Save(*Checkpoint) error
+}
+
+// FileCheckpointStore is CheckpointStore keeping Checkpoint as JSON file.
+type FileCheckpointStore struct {
+	path string
+}
+
+// NewFileCheckpointStore creates FileCheckpointStore using file at given path.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// Load implements CheckpointStore interface.
+func (s *FileCheckpointStore) Load() (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read checkpoint: %v", err)
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal checkpoint: %v", err)
+	}
+	if c.Chats == nil {
+		c.Chats = make(map[string]ChatCheckpoint)
+	}
+	return &c, nil
+}
+
+// Save implements CheckpointStore interface.
+func (s *FileCheckpointStore) Save(c *Checkpoint) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("couldn't write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("couldn't write checkpoint: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+// Package polling implements detection of chat changes by polling Agent Chat API, for environments
+// which can't receive webhooks.
+//
+// Detected changes are reported as webhooks with the same payload structures WebhookHandler produces,
+// so Poller can be used as events.Poller.
+package polling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/livechat/lc-sdk-go/v6/agent"
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+const (
+	chatsPageSize   = 100
+	threadsPageSize = 10
+	// maxListedThreads limits number of threads listed for a chat whose checkpoint thread can't be found,
+	// eg. when Poller was stopped for a long time. Only the latest threads are reported then.
+	maxListedThreads = 5 * threadsPageSize
+)
+
+// Poller detects new chats, new events and chat deactivations since the last committed Checkpoint.
+type Poller struct {
+	api        *agent.API
+	store      CheckpointStore
+	payloads   *webhooks.PayloadRegistry
+	checkpoint *Checkpoint
+	pending    *Checkpoint
+}
+
+// NewPoller creates Poller using given Agent Chat API client. Checkpoint is loaded from given store,
+// which might be nil if Checkpoint shouldn't be persisted.
+//
+// Without saved Checkpoint, the first Poll only records current state of chats and reports no changes.
+func NewPoller(api *agent.API, store CheckpointStore) (*Poller, error) {
+	p := &Poller{
+		api:      api,
+		store:    store,
+		payloads: webhooks.NewPayloadRegistry(),
+	}
+	if store != nil {
+		c, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		p.checkpoint = c
+	}
+	return p, nil
+}
+
+// Checkpoint returns the last committed Checkpoint, or nil if nothing was committed yet.
+func (p *Poller) Checkpoint() *Checkpoint {
+	if p.checkpoint == nil {
+		return nil
+	}
+	return p.checkpoint.clone()
+}
+
+// Poll implements events.Poller interface.
+//
+// It returns incoming_chat, incoming_event and chat_deactivated webhooks describing changes since the last
+// committed Checkpoint. Until Commit is called, subsequent calls report the same changes again.
+//
+// Chats are listed from the most recent threads and listing stops at chats with threads older than
+// the Checkpoint, once all its active chats are found. Without Checkpoint, all chats are listed.
+func (p *Poller) Poll(ctx context.Context) ([]*webhooks.Webhook, error) {
+	baseline := p.checkpoint == nil
+	previous := p.checkpoint
+	if baseline {
+		previous = &Checkpoint{Chats: make(map[string]ChatCheckpoint)}
+	}
+	summaries, complete, err := p.listChats(ctx, previous, baseline)
+	if err != nil {
+		return nil, err
+	}
+	next := previous.clone()
+
+	var whs []*webhooks.Webhook
+	listed := make(map[string]bool, len(summaries))
+	for _, s := range summaries {
+		current, ok := summaryCheckpoint(s)
+		if !ok {
+			continue
+		}
+		listed[s.ID] = true
+		last, known := previous.Chats[s.ID]
+		if baseline {
+			next.Chats[s.ID] = current
+			continue
+		}
+		if !known && current.ThreadCreatedAt.Before(previous.ThreadsSince) {
+			// Chat was pruned from the checkpoint and has no new threads.
+			continue
+		}
+		if known && last.ThreadID == current.ThreadID && last.ThreadActive == current.ThreadActive && last.LastEventID == current.LastEventID {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chatWhs, state, err := p.chatChanges(s, last, known)
+		if err != nil {
+			return nil, err
+		}
+		whs = append(whs, chatWhs...)
+		if state.ThreadID == current.ThreadID {
+			state.ThreadCreatedAt = current.ThreadCreatedAt
+		}
+		next.Chats[s.ID] = state
+	}
+	if complete {
+		// Chats missing in complete listing are no longer accessible.
+		for id := range next.Chats {
+			if !listed[id] {
+				delete(next.Chats, id)
+			}
+		}
+	}
+	next.prune()
+	p.pending = next
+	return whs, nil
+}
+
+// Commit implements events.Poller interface. It saves Checkpoint in the store.
+func (p *Poller) Commit() error {
+	if p.pending == nil {
+		return nil
+	}
+	if p.store != nil {
+		if err := p.store.Save(p.pending); err != nil {
+			return err
+		}
+	}
+	p.checkpoint, p.pending = p.pending, nil
+	return nil
+}
+
+// listChats lists chats sorted by creation time of their last thread, the most recent first. It stops when
+// the rest of chats haven't changed since given checkpoint, and reports whether all chats were listed.
+func (p *Poller) listChats(ctx context.Context, checkpoint *Checkpoint, all bool) ([]agent.ChatSummary, bool, error) {
+	active := make(map[string]bool)
+	for id, chat := range checkpoint.Chats {
+		if chat.ThreadActive {
+			active[id] = true
+		}
+	}
+
+	var summaries []agent.ChatSummary
+	pageID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		page, _, _, nextPage, err := p.api.ListChats(agent.NewChatsFilters(), "desc", pageID, chatsPageSize)
+		if err != nil {
+			return nil, false, fmt.Errorf("couldn't list chats: %v", err)
+		}
+		summaries = append(summaries, page...)
+		if nextPage == "" {
+			return summaries, true, nil
+		}
+		for _, s := range page {
+			delete(active, s.ID)
+		}
+		if !all && len(active) == 0 && len(page) > 0 {
+			last := page[len(page)-1].LastThreadSummary
+			if last != nil && last.CreatedAt.Before(checkpoint.ThreadsSince) {
+				return summaries, false, nil
+			}
+		}
+		pageID = nextPage
+	}
+}
+
+// listThreads returns threads of given chat created since the thread with given ID (inclusive), oldest first.
+// If threadID is empty, only the latest thread is returned. At most maxListedThreads threads are returned.
+func (p *Poller) listThreads(chatID, threadID string) ([]agent.Thread, error) {
+	var threads []agent.Thread
+	pageID := ""
+	limit := uint(threadsPageSize)
+	if threadID == "" {
+		limit = 1
+	}
+	for {
+		page, _, _, nextPage, err := p.api.ListThreads(chatID, "desc", pageID, limit, 0, nil)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list threads of chat %v: %v", chatID, err)
+		}
+		done := false
+		for _, t := range page {
+			threads = append(threads, t)
+			if t.ID == threadID || threadID == "" || len(threads) == maxListedThreads {
+				done = true
+				break
+			}
+		}
+		if done || nextPage == "" {
+			break
+		}
+		pageID = nextPage
+	}
+	for i, j := 0, len(threads)-1; i < j; i, j = i+1, j-1 {
+		threads[i], threads[j] = threads[j], threads[i]
+	}
+	return threads, nil
+}
+
+func (p *Poller) chatChanges(s agent.ChatSummary, last ChatCheckpoint, known bool) ([]*webhooks.Webhook, ChatCheckpoint, error) {
+	state := last
+	since := ""
+	if known {
+		since = last.ThreadID
+	}
+	threads, err := p.listThreads(s.ID, since)
+	if err != nil {
+		return nil, state, err
+	}
+
+	var whs []*webhooks.Webhook
+	add := func(action configuration.WebhookAction, payload interface{}) error {
+		wh, err := p.newWebhook(action, payload)
+		if err != nil {
+			return err
+		}
+		whs = append(whs, wh)
+		return nil
+	}
+	for _, t := range threads {
+		if known && t.ID == last.ThreadID {
+			for _, e := range eventsAfter(t.Events, last) {
+				if err := add(configuration.IncomingEvent, map[string]interface{}{
+					"chat_id":   s.ID,
+					"thread_id": t.ID,
+					"event":     e,
+				}); err != nil {
+					return nil, state, err
+				}
+			}
+		} else {
+			if err := add(configuration.IncomingChat, map[string]interface{}{
+				"chat": map[string]interface{}{
+					"id":          s.ID,
+					"users":       s.Users,
+					"properties":  s.Properties,
+					"access":      s.Access,
+					"is_followed": s.IsFollowed,
+					"thread":      webhooks.ThreadFromObject(t),
+				},
+			}); err != nil {
+				return nil, state, err
+			}
+		}
+		wasActive := t.ID != last.ThreadID || last.ThreadActive
+		if wasActive && !t.Active {
+			if err := add(configuration.ChatDeactivated, map[string]interface{}{
+				"chat_id":   s.ID,
+				"thread_id": t.ID,
+			}); err != nil {
+				return nil, state, err
+			}
+		}
+
+		state.ThreadID = t.ID
+		state.ThreadActive = t.Active
+		if len(t.Events) > 0 {
+			e := t.Events[len(t.Events)-1]
+			state.LastEventID = e.ID
+			state.LastEventAt = e.CreatedAt
+		}
+	}
+	return whs, state, nil
+}
+
+func (p *Poller) newWebhook(action configuration.WebhookAction, payload interface{}) (*webhooks.Webhook, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal %v payload: %v", action, err)
+	}
+	decoded := p.payloads.New(action)
+	if err := json.Unmarshal(raw, decoded); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal %v payload: %v", action, err)
+	}
+	return &webhooks.Webhook{
+		Action:     string(action),
+		RawPayload: raw,
+		Payload:    decoded,
+	}, nil
+}
+
+// eventsAfter returns events following the last event recorded in the checkpoint.
+func eventsAfter(events []*agent.Event, last ChatCheckpoint) []*agent.Event {
+	if last.LastEventID != "" {
+		for i, e := range events {
+			if e.ID == last.LastEventID {
+				return events[i+1:]
+			}
+		}
+	}
+	var after []*agent.Event
+	for _, e := range events {
+		if e.CreatedAt.After(last.LastEventAt) {
+			after = append(after, e)
+		}
+	}
+	return after
+}
+
+// summaryCheckpoint returns state of chat as reported in its summary.
+func summaryCheckpoint(s agent.ChatSummary) (ChatCheckpoint, bool) {
+	if s.LastThreadSummary == nil {
+		return ChatCheckpoint{}, false
+	}
+	c := ChatCheckpoint{
+		ThreadID:        s.LastThreadSummary.ID,
+		ThreadActive:    s.LastThreadSummary.Active,
+		ThreadCreatedAt: s.LastThreadSummary.CreatedAt,
+	}
+	for _, last := range s.LastEventPerType {
+		if last.ThreadID == c.ThreadID && !last.Event.CreatedAt.Before(c.LastEventAt) {
+			c.LastEventID = last.Event.ID
+			c.LastEventAt = last.Event.CreatedAt
+		}
+	}
+	return c, true
+}
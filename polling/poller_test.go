@@ -0,0 +1,257 @@
+package polling_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/agent"
+	"github.com/livechat/lc-sdk-go/v6/authorization"
+	"github.com/livechat/lc-sdk-go/v6/events"
+	"github.com/livechat/lc-sdk-go/v6/polling"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+var _ events.Poller = (*polling.Poller)(nil)
+
+type thread struct {
+	ID        string              `json:"id"`
+	Active    bool                `json:"active"`
+	CreatedAt string              `json:"created_at,omitempty"`
+	Events    []map[string]string `json:"events"`
+}
+
+type chatsMock struct {
+	threads        map[string][]*thread
+	listChatsCalls int
+}
+
+// chatIDs returns IDs of chats with threads, sorted by creation time of their last thread, the most recent first.
+func (m *chatsMock) chatIDs() []string {
+	var ids []string
+	for id, threads := range m.threads {
+		if len(threads) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	createdAt := func(id string) string {
+		return m.threads[id][len(m.threads[id])-1].CreatedAt
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if createdAt(ids[i]) != createdAt(ids[j]) {
+			return createdAt(ids[i]) > createdAt(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func (m *chatsMock) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp interface{}
+	switch path.Base(req.URL.Path) {
+	case "list_chats":
+		m.listChatsCalls++
+		var r struct {
+			PageID string `json:"page_id"`
+			Limit  int    `json:"limit"`
+		}
+		json.NewDecoder(req.Body).Decode(&r)
+		ids := m.chatIDs()
+		start, _ := strconv.Atoi(r.PageID)
+		end := start + r.Limit
+		nextPage := strconv.Itoa(end)
+		if end >= len(ids) {
+			end, nextPage = len(ids), ""
+		}
+		var summaries []interface{}
+		for _, id := range ids[start:end] {
+			threads := m.threads[id]
+			last := threads[len(threads)-1]
+			lastSummary := map[string]interface{}{"id": last.ID, "active": last.Active}
+			if last.CreatedAt != "" {
+				lastSummary["created_at"] = last.CreatedAt
+			}
+			summary := map[string]interface{}{
+				"id":                  id,
+				"users":               []interface{}{map[string]string{"id": "agent@example.com", "type": "agent"}},
+				"last_thread_summary": lastSummary,
+			}
+			if len(last.Events) > 0 {
+				e := last.Events[len(last.Events)-1]
+				summary["last_event_per_type"] = map[string]interface{}{
+					"message": map[string]interface{}{"thread_id": last.ID, "event": e},
+				}
+			}
+			summaries = append(summaries, summary)
+		}
+		resp = map[string]interface{}{"chats_summary": summaries, "next_page_id": nextPage}
+	case "list_threads":
+		var r struct {
+			ChatID string `json:"chat_id"`
+		}
+		json.NewDecoder(req.Body).Decode(&r)
+		var threads []*thread
+		for i := len(m.threads[r.ChatID]) - 1; i >= 0; i-- {
+			threads = append(threads, m.threads[r.ChatID][i])
+		}
+		resp = map[string]interface{}{"threads": threads}
+	}
+	body, _ := json.Marshal(resp)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBuffer(body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func message(id, createdAt string) map[string]string {
+	return map[string]string{"id": id, "type": "message", "text": id, "created_at": createdAt}
+}
+
+func newPoller(t *testing.T, mock *chatsMock, store polling.CheckpointStore) *polling.Poller {
+	api, err := agent.NewAPI(func() *authorization.Token {
+		return &authorization.Token{AccessToken: "access_token", Region: "region"}
+	}, &http.Client{Transport: mock}, "client_id")
+	if err != nil {
+		t.Fatalf("API creation failed: %v", err)
+	}
+	p, err := polling.NewPoller(api, store)
+	if err != nil {
+		t.Fatalf("Poller creation failed: %v", err)
+	}
+	return p
+}
+
+func poll(t *testing.T, p *polling.Poller) []string {
+	whs, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	var changes []string
+	for _, wh := range whs {
+		switch payload := wh.Payload.(type) {
+		case *webhooks.IncomingChat:
+			changes = append(changes, wh.Action+" "+payload.Chat.ID+" "+payload.Chat.Threads[0].ID)
+		case *webhooks.IncomingEvent:
+			changes = append(changes, wh.Action+" "+payload.ChatID+" "+payload.Event.ID)
+		case *webhooks.ChatDeactivated:
+			changes = append(changes, wh.Action+" "+payload.ChatID+" "+payload.ThreadID)
+		}
+	}
+	return changes
+}
+
+func expectChanges(t *testing.T, actual []string, expected ...string) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("invalid changes: %v, expected: %v", actual, expected)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("invalid changes: %v, expected: %v", actual, expected)
+		}
+	}
+}
+
+func TestPollerDetectsChanges(t *testing.T) {
+	mock := &chatsMock{threads: map[string][]*thread{
+		"chat_1": {{ID: "T1", Active: true, Events: []map[string]string{message("e1", "2021-01-01T10:00:00Z")}}},
+	}}
+	store := polling.NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	p := newPoller(t, mock, store)
+
+	expectChanges(t, poll(t, p))
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	t1 := mock.threads["chat_1"][0]
+	t1.Events = append(t1.Events, message("e2", "2021-01-01T10:01:00Z"))
+	mock.threads["chat_2"] = []*thread{{ID: "T2", Active: true, Events: []map[string]string{message("e3", "2021-01-01T10:02:00Z")}}}
+	expectChanges(t, poll(t, p), "incoming_event chat_1 e2", "incoming_chat chat_2 T2")
+	expectChanges(t, poll(t, p), "incoming_event chat_1 e2", "incoming_chat chat_2 T2")
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	expectChanges(t, poll(t, p))
+
+	t1.Active = false
+	mock.threads["chat_2"] = append(mock.threads["chat_2"], &thread{ID: "T3", Active: true, Events: []map[string]string{message("e4", "2021-01-01T10:03:00Z")}})
+	expectChanges(t, poll(t, p), "chat_deactivated chat_1 T1", "incoming_chat chat_2 T3")
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	resumed := newPoller(t, mock, store)
+	expectChanges(t, poll(t, resumed))
+	if c := resumed.Checkpoint(); c.Chats["chat_2"].ThreadID != "T3" || c.Chats["chat_2"].LastEventID != "e4" {
+		t.Errorf("invalid checkpoint: %+v", c)
+	}
+}
+
+func TestPollerStopsListingAtUnchangedChats(t *testing.T) {
+	mock := &chatsMock{threads: make(map[string][]*thread)}
+	for i := 0; i < 250; i++ {
+		mock.threads[fmt.Sprintf("old_%03d", i)] = []*thread{{ID: fmt.Sprintf("OT%03d", i), CreatedAt: "2021-01-01T08:00:00Z"}}
+	}
+	mock.threads["chat_1"] = []*thread{{ID: "T1", Active: true, CreatedAt: "2021-01-01T10:00:00Z", Events: []map[string]string{message("e1", "2021-01-01T10:00:00Z")}}}
+	mock.threads["chat_2"] = []*thread{{ID: "T2", CreatedAt: "2021-01-01T09:00:00Z"}}
+	p := newPoller(t, mock, nil)
+
+	expectChanges(t, poll(t, p))
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if c := p.Checkpoint(); len(c.Chats) != 1 || c.Chats["chat_1"].ThreadID != "T1" {
+		t.Errorf("inactive chats with older threads should be pruned: %+v", c)
+	}
+
+	mock.listChatsCalls = 0
+	t1 := mock.threads["chat_1"][0]
+	t1.Events = append(t1.Events, message("e2", "2021-01-01T10:01:00Z"))
+	expectChanges(t, poll(t, p), "incoming_event chat_1 e2")
+	if mock.listChatsCalls != 1 {
+		t.Errorf("listing should stop at unchanged chats, got %v pages", mock.listChatsCalls)
+	}
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	mock.threads["old_100"] = append(mock.threads["old_100"], &thread{ID: "NT", Active: true, CreatedAt: "2021-01-01T11:00:00Z"})
+	expectChanges(t, poll(t, p), "incoming_chat old_100 NT")
+}
+
+func TestPollerLimitsListedHistory(t *testing.T) {
+	mock := &chatsMock{threads: map[string][]*thread{
+		"chat_1": {{ID: "T1", Active: true, CreatedAt: "2021-01-01T10:00:00Z"}},
+	}}
+	p := newPoller(t, mock, nil)
+	expectChanges(t, poll(t, p))
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	var threads []*thread
+	for i := 0; i < 80; i++ {
+		threads = append(threads, &thread{ID: fmt.Sprintf("NT%02d", i), Active: true, CreatedAt: fmt.Sprintf("2021-01-02T%02d:%02d:00Z", 10+i/60, i%60)})
+	}
+	mock.threads["chat_1"] = threads
+	incoming := 0
+	for _, change := range poll(t, p) {
+		if strings.HasPrefix(change, "incoming_chat") {
+			incoming++
+		}
+	}
+	if incoming == 0 || incoming >= len(threads) {
+		t.Errorf("only the latest threads should be reported, got %v", incoming)
+	}
+}
@@ -0,0 +1,82 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+)
+
+// AdditionalData represents decoded additional_data of webhook, requested with configuration.Webhook's AdditionalData.
+type AdditionalData struct {
+	ChatProperties      Properties
+	ChatPresenceUserIDs []string
+	available           map[string]bool
+}
+
+// Has reports whether given additional data (eg. configuration.ChatPropertiesData) is available in webhook.
+func (d *AdditionalData) Has(name string) bool {
+	return d != nil && d.available[name]
+}
+
+// decodeAdditionalData decodes raw additional data. Data listed in requested is considered available,
+// even if it was omitted in raw additional data (which means it's empty).
+func decodeAdditionalData(raw json.RawMessage, requested []string) (*AdditionalData, error) {
+	d := &AdditionalData{available: make(map[string]bool)}
+	for _, name := range requested {
+		d.available[name] = true
+	}
+
+	var fields map[string]json.RawMessage
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal additional data: %v", err)
+		}
+	}
+	for name, value := range fields {
+		var target interface{}
+		switch name {
+		case configuration.ChatPropertiesData:
+			target = &d.ChatProperties
+		case configuration.ChatPresenceUserIDsData:
+			target = &d.ChatPresenceUserIDs
+		default:
+			continue
+		}
+		if err := json.Unmarshal(value, target); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal additional data %v: %v", name, err)
+		}
+		d.available[name] = true
+	}
+	return d, nil
+}
+
+// ChatProperties returns properties of the chat webhook relates to and whether they're available
+// in webhook's additional data.
+func (wh *Webhook) ChatProperties() (Properties, bool) {
+	if !wh.Additional.Has(configuration.ChatPropertiesData) {
+		return nil, false
+	}
+	return wh.Additional.ChatProperties, true
+}
+
+// ChatPresenceUserIDs returns IDs of users present in the chat webhook relates to and whether they're available
+// in webhook's additional data.
+func (wh *Webhook) ChatPresenceUserIDs() ([]string, bool) {
+	if !wh.Additional.Has(configuration.ChatPresenceUserIDsData) {
+		return nil, false
+	}
+	return wh.Additional.ChatPresenceUserIDs, true
+}
+
+// IsUserPresent reports whether user with given ID (eg. a bot) is present in the chat webhook relates to.
+// The second returned value reports whether chat presence is available in webhook's additional data.
+func (wh *Webhook) IsUserPresent(userID string) (present, known bool) {
+	ids, known := wh.ChatPresenceUserIDs()
+	for _, id := range ids {
+		if id == userID {
+			return true, known
+		}
+	}
+	return false, known
+}
@@ -0,0 +1,110 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+func additionalDataWebhook(additionalData string) []byte {
+	return []byte(`{
+		"webhook_id": "wh",
+		"action": "incoming_event",
+		"payload": {"chat_id": "PS0X0L086G", "thread_id": "PZ070E0W1B", "event": {"id": "PZ070E0W1B_3", "type": "message", "text": "hi"}},
+		"additional_data": ` + additionalData + `
+	}`)
+}
+
+func TestAdditionalDataIsDecoded(t *testing.T) {
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		props, ok := wh.ChatProperties()
+		if !ok {
+			return errors.New("chat properties should be available")
+		}
+		if props["routing"]["continuous"] != true {
+			return errors.New("invalid chat properties")
+		}
+		present, known := wh.IsUserPresent("bot_id")
+		if !present || !known {
+			return errors.New("bot should be present")
+		}
+		present, known = wh.IsUserPresent("other_bot_id")
+		if present || !known {
+			return errors.New("other bot shouldn't be present")
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().WithAction("incoming_event", handler, "")
+	h := webhooks.NewWebhookHandler(cfg)
+	body := additionalDataWebhook(`{
+		"chat_properties": {"routing": {"continuous": true}},
+		"chat_presence_user_ids": ["bot_id", "agent@example.com"]
+	}`)
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(body)))
+	if resp.Code != http.StatusOK {
+		t.Errorf("invalid code: %v, body: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestAdditionalDataAvailabilityIsDrivenByRequestedData(t *testing.T) {
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		if _, ok := wh.ChatProperties(); ok {
+			return errors.New("chat properties shouldn't be available")
+		}
+		ids, ok := wh.ChatPresenceUserIDs()
+		if !ok {
+			return errors.New("requested chat presence should be available")
+		}
+		if len(ids) != 0 {
+			return errors.New("chat presence should be empty")
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().
+		WithAction("incoming_event", handler, "").
+		WithAdditionalData("incoming_event", configuration.ChatPresenceUserIDsData)
+	h := webhooks.NewWebhookHandler(cfg)
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(additionalDataWebhook(`{}`))))
+	if resp.Code != http.StatusOK {
+		t.Errorf("invalid code: %v, body: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestRejectWebhooksWithMalformedAdditionalData(t *testing.T) {
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error { return nil }
+	cfg := webhooks.NewConfiguration().WithAction("incoming_event", handler, "")
+	h := webhooks.NewWebhookHandler(cfg)
+	body := additionalDataWebhook(`{"chat_presence_user_ids": "bot_id"}`)
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(body)))
+	if resp.Code != http.StatusInternalServerError {
+		t.Errorf("invalid code: %v", resp.Code)
+	}
+}
+
+func TestAdditionalDataDeclarationIsIndependentOfHandler(t *testing.T) {
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		if _, ok := wh.ChatPresenceUserIDs(); !ok {
+			return errors.New("requested chat presence should be available")
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().
+		WithAdditionalData("incoming_event", configuration.ChatPresenceUserIDsData).
+		WithAction("incoming_event", func(context.Context, *webhooks.Webhook) error { return nil }, "").
+		WithRawAction("incoming_event", handler, "")
+	h := webhooks.NewWebhookHandler(cfg)
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(additionalDataWebhook(`{}`))))
+	if resp.Code != http.StatusOK {
+		t.Errorf("invalid code: %v, body: %v", resp.Code, resp.Body)
+	}
+}
@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/metrics"
+)
+
+// DeadLetter represents webhook which Handler returned an error.
+type DeadLetter struct {
+	ID       string          `json:"id"`
+	Action   string          `json:"action"`
+	Body     json.RawMessage `json:"body"`
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// DeadLetterSink captures webhooks which processing failed, see Configuration.WithDeadLetterSink.
+type DeadLetterSink interface {
+	Put(*DeadLetter) error
+}
+
+// DeadLetterStore is DeadLetterSink that allows to read captured webhooks back, eg. to replay them
+// with ReplayDeadLetters.
+type DeadLetterStore interface {
+	DeadLetterSink
+	// List returns all stored dead letters, oldest first.
+	List() ([]*DeadLetter, error)
+	// Remove removes dead letter with given ID from the store.
+	Remove(id string) error
+}
+
+func newDeadLetter(action string, body []byte, err string) *DeadLetter {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	now := time.Now().UTC()
+	return &DeadLetter{
+		ID:       fmt.Sprintf("%d-%s", now.UnixNano(), hex.EncodeToString(suffix)),
+		Action:   action,
+		Body:     body,
+		Error:    err,
+		FailedAt: now,
+	}
+}
+
+// WithDeadLetterSink allows to attach DeadLetterSink, which captures raw body of every webhook
+// for which Handler returned an error.
+func (cfg *Configuration) WithDeadLetterSink(sink DeadLetterSink) *Configuration {
+	cfg.deadLetters = sink
+	return cfg
+}
+
+// FileDeadLetterStore is DeadLetterStore keeping each dead letter as JSON file in a directory.
+type FileDeadLetterStore struct {
+	dir string
+}
+
+// NewFileDeadLetterStore creates FileDeadLetterStore in given directory. The directory is created if it doesn't exist.
+func NewFileDeadLetterStore(dir string) (*FileDeadLetterStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("couldn't create dead letter directory: %v", err)
+	}
+	return &FileDeadLetterStore{dir: dir}, nil
+}
+
+// Put implements DeadLetterSink interface.
+func (s *FileDeadLetterStore) Put(dl *DeadLetter) error {
+	data, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+	tmpPath := s.path(dl.ID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("couldn't write dead letter: %v", err)
+	}
+	if err := os.Rename(tmpPath, s.path(dl.ID)); err != nil {
+		return fmt.Errorf("couldn't write dead letter: %v", err)
+	}
+	return nil
+}
+
+// List implements DeadLetterStore interface.
+func (s *FileDeadLetterStore) List() ([]*DeadLetter, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read dead letter directory: %v", err)
+	}
+	var dls []*DeadLetter
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read dead letter: %v", err)
+		}
+		var dl DeadLetter
+		if err := json.Unmarshal(data, &dl); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal dead letter %v: %v", e.Name(), err)
+		}
+		dls = append(dls, &dl)
+	}
+	sort.Slice(dls, func(i, j int) bool {
+		return dls[i].FailedAt.Before(dls[j].FailedAt)
+	})
+	return dls, nil
+}
+
+// Remove implements DeadLetterStore interface.
+func (s *FileDeadLetterStore) Remove(id string) error {
+	if err := os.Remove(s.path(id)); err != nil {
+		return fmt.Errorf("couldn't remove dead letter: %v", err)
+	}
+	return nil
+}
+
+func (s *FileDeadLetterStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+// ReplayResult summarizes ReplayDeadLetters execution.
+type ReplayResult struct {
+	Replayed int
+	// Failed maps IDs of dead letters that failed again to errors of their processing.
+	Failed map[string]error
+}
+
+// ReplayDeadLetters processes all dead letters from store with given Configuration, oldest first.
+//
+// Successfully processed dead letters are removed from the store. Dead letters that failed again are left intact.
+// Replaying stops when ctx is done.
+func ReplayDeadLetters(ctx context.Context, cfg *Configuration, store DeadLetterStore) (*ReplayResult, error) {
+	dls, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{Failed: make(map[string]error)}
+	for _, dl := range dls {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if perr := cfg.process(ctx, dl.Body, &metrics.WebhookStats{}); perr != nil {
+			result.Failed[dl.ID] = perr
+			continue
+		}
+		if err := store.Remove(dl.ID); err != nil {
+			return result, err
+		}
+		result.Replayed++
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+func TestFailedWebhooksAreStoredAndReplayed(t *testing.T) {
+	store, err := webhooks.NewFileDeadLetterStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("couldn't create store: %v", err)
+	}
+	fixed := false
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		if !fixed {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+	action := configuration.IncomingEvent
+	cfg := webhooks.NewConfiguration().WithAction(action, handler, "").WithDeadLetterSink(store)
+	h := webhooks.NewWebhookHandler(cfg)
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Fatalf("Missing test payload for action %v", action)
+	}
+
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload)))
+	if resp.Code != http.StatusInternalServerError {
+		t.Errorf("invalid code: %v", resp.Code)
+	}
+
+	dls, err := store.List()
+	if err != nil {
+		t.Fatalf("couldn't list dead letters: %v", err)
+	}
+	if len(dls) != 1 {
+		t.Fatalf("invalid number of dead letters: %v", len(dls))
+	}
+	if dls[0].Action != string(action) {
+		t.Errorf("invalid dead letter action: %v", dls[0].Action)
+	}
+	if dls[0].Error != "webhook handler error: handler failed" {
+		t.Errorf("invalid dead letter error: %v", dls[0].Error)
+	}
+	if dls[0].FailedAt.IsZero() {
+		t.Error("dead letter failure time not set")
+	}
+
+	result, err := webhooks.ReplayDeadLetters(context.Background(), cfg, store)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if result.Replayed != 0 || len(result.Failed) != 1 {
+		t.Errorf("invalid replay result: %+v", result)
+	}
+
+	fixed = true
+	result, err = webhooks.ReplayDeadLetters(context.Background(), cfg, store)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if result.Replayed != 1 || len(result.Failed) != 0 {
+		t.Errorf("invalid replay result: %+v", result)
+	}
+	if dls, _ := store.List(); len(dls) != 0 {
+		t.Errorf("replayed dead letters were not removed: %v", len(dls))
+	}
+}
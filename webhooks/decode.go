@@ -0,0 +1,42 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// decodeWebhook decodes webhook body, keeping its payload in RawPayload.
+//
+// Payload is decoded into its structure only after webhook's secret key is validated (see decodePayload),
+// so that unauthenticated webhooks are rejected as such, regardless of their payload.
+func decodeWebhook(body []byte) (*Webhook, *Error) {
+	// Webhook's Payload field would catch "Payload" key, so the envelope is decoded without it.
+	var envelope struct {
+		WebhookID      string          `json:"webhook_id"`
+		SecretKey      string          `json:"secret_key"`
+		Action         string          `json:"action"`
+		OrganizationID string          `json:"organization_id"`
+		AdditionalData json.RawMessage `json:"additional_data"`
+		RawPayload     json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, newError(ErrCodeMalformedWebhook, fmt.Errorf("couldn't unmarshal webhook base: %v", err))
+	}
+	return &Webhook{
+		WebhookID:      envelope.WebhookID,
+		SecretKey:      envelope.SecretKey,
+		Action:         envelope.Action,
+		OrganizationID: envelope.OrganizationID,
+		AdditionalData: envelope.AdditionalData,
+		RawPayload:     envelope.RawPayload,
+	}, nil
+}
+
+// decodePayload decodes webhook's RawPayload into given payload structure and sets it as webhook's Payload.
+func decodePayload(wh *Webhook, payload interface{}) *Error {
+	if err := json.Unmarshal(wh.RawPayload, payload); err != nil {
+		return newError(ErrCodeMalformedPayload, fmt.Errorf("couldn't unmarshal webhook payload: %v", err)).withAction(wh.Action)
+	}
+	wh.Payload = payload
+	return nil
+}
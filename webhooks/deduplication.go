@@ -0,0 +1,308 @@
+package webhooks
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DeduplicationStore keeps track of already processed webhooks.
+//
+// It's used by WebhookHandler to skip webhooks redelivered by LiveChat, see Configuration.WithDeduplication.
+//
+// Key is reserved before webhook's Handler is called. WebhookHandler makes webhooks with the same key wait
+// until the first one is processed, but only within a single process - if the store is shared by many
+// application instances, redelivery received by other instance while the first attempt is still processed
+// is skipped, and the webhook is lost if the first attempt fails.
+type DeduplicationStore interface {
+	// Reserve records key as processed and reports whether it was recorded for the first time.
+	Reserve(key string) (bool, error)
+	// Release removes key from the store, so that webhook with such key is processed again.
+	Release(key string) error
+}
+
+// DeduplicationKeyFunc is used to compute key identifying given webhook delivery.
+type DeduplicationKeyFunc func(*Webhook) string
+
+// DefaultDeduplicationKey identifies webhook by its WebhookID and, depending on the action,
+// by ID of the event, by ID of the chat and thread or by hash of the raw payload.
+func DefaultDeduplicationKey(wh *Webhook) string {
+	switch p := wh.Payload.(type) {
+	case *IncomingEvent:
+		if p.Event.ID != "" {
+			return wh.WebhookID + ":" + p.Event.ID
+		}
+	case *IncomingChat:
+		if len(p.Chat.Threads) > 0 {
+			return wh.WebhookID + ":" + p.Chat.ID + ":" + p.Chat.Threads[0].ID
+		}
+	case *ChatDeactivated:
+		return wh.WebhookID + ":" + p.ChatID + ":" + p.ThreadID
+	}
+	sum := sha256.Sum256(wh.RawPayload)
+	return wh.WebhookID + ":" + hex.EncodeToString(sum[:])
+}
+
+// keyLocks allows to process webhooks with the same deduplication key one by one.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func newKeyLocks() *keyLocks {
+	return &keyLocks{locks: make(map[string]*keyLock)}
+}
+
+// lock waits until given key is unlocked or ctx is done. If it succeeds, returned function must be called
+// to unlock the key.
+func (l *keyLocks) lock(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	kl, exists := l.locks[key]
+	if !exists {
+		kl = &keyLock{ch: make(chan struct{}, 1)}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	select {
+	case kl.ch <- struct{}{}:
+		return func() {
+			<-kl.ch
+			l.release(key, kl)
+		}, nil
+	case <-ctx.Done():
+		l.release(key, kl)
+		return nil, ctx.Err()
+	}
+}
+
+func (l *keyLocks) release(key string, kl *keyLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kl.refs--
+	if kl.refs == 0 {
+		delete(l.locks, key)
+	}
+}
+
+// MemoryDeduplicationStore is DeduplicationStore keeping keys in memory.
+type MemoryDeduplicationStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryDeduplicationStore creates MemoryDeduplicationStore that keeps keys for given ttl.
+func NewMemoryDeduplicationStore(ttl time.Duration) *MemoryDeduplicationStore {
+	return &MemoryDeduplicationStore{
+		ttl:       ttl,
+		keys:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Reserve implements DeduplicationStore interface.
+func (s *MemoryDeduplicationStore) Reserve(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	if expiresAt, exists := s.keys[key]; exists && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.keys[key] = now.Add(s.ttl)
+	return true, nil
+}
+
+// Release implements DeduplicationStore interface.
+func (s *MemoryDeduplicationStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+	return nil
+}
+
+func (s *MemoryDeduplicationStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.removeExpired(now)
+}
+
+func (s *MemoryDeduplicationStore) removeExpired(now time.Time) {
+	for key, expiresAt := range s.keys {
+		if !now.Before(expiresAt) {
+			delete(s.keys, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// FileDeduplicationStore is DeduplicationStore keeping keys in memory and persisting them in a file.
+//
+// Keys are appended to the file as they're reserved and released. The file is compacted when the store is created
+// and whenever it contains more than CompactionThreshold outdated records (released or expired keys), so that it
+// doesn't grow without bound in a long-running application.
+type FileDeduplicationStore struct {
+	memory *MemoryDeduplicationStore
+	path   string
+
+	mu      sync.Mutex
+	file    *os.File
+	records int
+}
+
+// CompactionThreshold is the number of outdated records in FileDeduplicationStore's file which triggers its compaction.
+const CompactionThreshold = 1000
+
+type deduplicationRecord struct {
+	Key       string `json:"key"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// NewFileDeduplicationStore creates FileDeduplicationStore that keeps keys for given ttl
+// and persists them in file at given path, so they survive application restarts.
+func NewFileDeduplicationStore(path string, ttl time.Duration) (*FileDeduplicationStore, error) {
+	mem := NewMemoryDeduplicationStore(ttl)
+
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var r deduplicationRecord
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("couldn't parse deduplication file: %v", err)
+			}
+			if r.ExpiresAt == 0 {
+				delete(mem.keys, r.Key)
+				continue
+			}
+			mem.keys[r.Key] = time.Unix(0, r.ExpiresAt)
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("couldn't read deduplication file: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("couldn't open deduplication file: %v", err)
+	}
+
+	s := &FileDeduplicationStore{
+		memory: mem,
+		path:   path,
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reserve implements DeduplicationStore interface.
+func (s *FileDeduplicationStore) Reserve(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first, err := s.memory.Reserve(key)
+	if err != nil || !first {
+		return first, err
+	}
+	if err := s.append(deduplicationRecord{Key: key, ExpiresAt: time.Now().Add(s.memory.ttl).UnixNano()}); err != nil {
+		s.memory.Release(key)
+		return false, err
+	}
+	return true, nil
+}
+
+// Release implements DeduplicationStore interface.
+func (s *FileDeduplicationStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memory.Release(key)
+	return s.append(deduplicationRecord{Key: key})
+}
+
+// Close closes underlying file.
+func (s *FileDeduplicationStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *FileDeduplicationStore) append(r deduplicationRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("couldn't write deduplication file: %v", err)
+	}
+	s.records++
+
+	s.memory.mu.Lock()
+	live := len(s.memory.keys)
+	s.memory.mu.Unlock()
+	if s.records-live > CompactionThreshold {
+		// The record is already persisted, so failed compaction is just retried with the next record.
+		s.compact()
+	}
+	return nil
+}
+
+// compact removes expired keys and replaces the file with one containing only reserved keys.
+func (s *FileDeduplicationStore) compact() error {
+	s.memory.mu.Lock()
+	s.memory.removeExpired(time.Now())
+	records := make([]deduplicationRecord, 0, len(s.memory.keys))
+	for key, expiresAt := range s.memory.keys {
+		records = append(records, deduplicationRecord{Key: key, ExpiresAt: expiresAt.UnixNano()})
+	}
+	s.memory.mu.Unlock()
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("couldn't create deduplication file: %v", err)
+	}
+	enc := json.NewEncoder(tmp)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			tmp.Close()
+			return fmt.Errorf("couldn't write deduplication file: %v", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("couldn't write deduplication file: %v", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("couldn't replace deduplication file: %v", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("couldn't open deduplication file: %v", err)
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = f
+	s.records = len(records)
+	return nil
+}
@@ -0,0 +1,244 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+func TestDeduplicationSkipsRedeliveredWebhooks(t *testing.T) {
+	calls := 0
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		calls++
+		return nil
+	}
+	action := configuration.IncomingEvent
+	cfg := webhooks.NewConfiguration().
+		WithAction(action, handler, "").
+		WithDeduplication(webhooks.NewMemoryDeduplicationStore(time.Minute), nil)
+	h := webhooks.NewWebhookHandler(cfg)
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Fatalf("Missing test payload for action %v", action)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp := httptest.NewRecorder()
+		h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload)))
+		if resp.Code != http.StatusOK {
+			t.Errorf("invalid code: %v", resp.Code)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("invalid number of handler calls: %v", calls)
+	}
+}
+
+func TestDeduplicationProcessesRedeliveredWebhookAfterHandlerError(t *testing.T) {
+	calls := 0
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		calls++
+		if calls == 1 {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+	action := configuration.IncomingEvent
+	cfg := webhooks.NewConfiguration().
+		WithAction(action, handler, "").
+		WithDeduplication(webhooks.NewMemoryDeduplicationStore(time.Minute), nil)
+	h := webhooks.NewWebhookHandler(cfg)
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Fatalf("Missing test payload for action %v", action)
+	}
+
+	for _, expectedCode := range []int{http.StatusInternalServerError, http.StatusOK} {
+		resp := httptest.NewRecorder()
+		h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload)))
+		if resp.Code != expectedCode {
+			t.Errorf("invalid code: %v", resp.Code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("invalid number of handler calls: %v", calls)
+	}
+}
+
+type failingReleaseStore struct {
+	webhooks.DeduplicationStore
+}
+
+func (failingReleaseStore) Release(key string) error {
+	return errors.New("disk full")
+}
+
+func TestDeduplicationReportsFailedRelease(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		return handlerErr
+	}
+	var received *webhooks.Error
+	action := configuration.IncomingEvent
+	cfg := webhooks.NewConfiguration().
+		WithAction(action, handler, "").
+		WithDeduplication(failingReleaseStore{webhooks.NewMemoryDeduplicationStore(time.Minute)}, nil).
+		WithStructuredErrorHandler(func(w http.ResponseWriter, err *webhooks.Error) {
+			received = err
+			w.WriteHeader(err.StatusCode)
+		})
+	h := webhooks.NewWebhookHandler(cfg)
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Fatalf("Missing test payload for action %v", action)
+	}
+
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload)))
+	if resp.Code != http.StatusInternalServerError {
+		t.Errorf("invalid code: %v", resp.Code)
+	}
+	if received == nil || received.Code != webhooks.ErrCodeDeduplication || !errors.Is(received, handlerErr) {
+		t.Fatalf("invalid error: %v", received)
+	}
+	if !strings.Contains(received.Error(), "disk full") {
+		t.Errorf("release error should be reported: %v", received)
+	}
+}
+
+func TestDefaultDeduplicationKey(t *testing.T) {
+	wh := &webhooks.Webhook{
+		WebhookID: "wh",
+		Payload:   &webhooks.IncomingEvent{Event: webhooks.Event{ID: "event_1"}},
+	}
+	if key := webhooks.DefaultDeduplicationKey(wh); key != "wh:event_1" {
+		t.Errorf("invalid key: %v", key)
+	}
+
+	a := &webhooks.Webhook{WebhookID: "wh", RawPayload: []byte(`{"id":"a"}`), Payload: &webhooks.AgentDeleted{}}
+	b := &webhooks.Webhook{WebhookID: "wh", RawPayload: []byte(`{"id":"b"}`), Payload: &webhooks.AgentDeleted{}}
+	if webhooks.DefaultDeduplicationKey(a) == webhooks.DefaultDeduplicationKey(b) {
+		t.Error("different payloads should have different keys")
+	}
+}
+
+func TestMemoryDeduplicationStoreExpiresKeys(t *testing.T) {
+	store := webhooks.NewMemoryDeduplicationStore(10 * time.Millisecond)
+	if first, _ := store.Reserve("key"); !first {
+		t.Error("key should be reserved for the first time")
+	}
+	if first, _ := store.Reserve("key"); first {
+		t.Error("key should already be reserved")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if first, _ := store.Reserve("key"); !first {
+		t.Error("key should expire")
+	}
+}
+
+func TestFileDeduplicationStorePersistsKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup")
+	store, err := webhooks.NewFileDeduplicationStore(path, time.Minute)
+	if err != nil {
+		t.Fatalf("couldn't create store: %v", err)
+	}
+	store.Reserve("kept")
+	store.Reserve("released")
+	store.Release("released")
+	store.Close()
+
+	store, err = webhooks.NewFileDeduplicationStore(path, time.Minute)
+	if err != nil {
+		t.Fatalf("couldn't reopen store: %v", err)
+	}
+	defer store.Close()
+	if first, _ := store.Reserve("kept"); first {
+		t.Error("kept key should be restored from file")
+	}
+	if first, _ := store.Reserve("released"); !first {
+		t.Error("released key should not be restored from file")
+	}
+}
+
+func TestDeduplicationRedeliveryWaitsForFirstAttempt(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	calls := 0
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-finish
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+	action := configuration.IncomingEvent
+	cfg := webhooks.NewConfiguration().
+		WithAction(action, handler, "").
+		WithDeduplication(webhooks.NewMemoryDeduplicationStore(time.Minute), nil)
+	h := webhooks.NewWebhookHandler(cfg)
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Fatalf("Missing test payload for action %v", action)
+	}
+
+	deliver := func(codes chan<- int) {
+		resp := httptest.NewRecorder()
+		h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload)))
+		codes <- resp.Code
+	}
+	first, redelivery := make(chan int, 1), make(chan int, 1)
+	go deliver(first)
+	<-started
+	go deliver(redelivery)
+	time.Sleep(20 * time.Millisecond)
+	close(finish)
+
+	if code := <-first; code != http.StatusInternalServerError {
+		t.Errorf("first attempt should fail, got %v", code)
+	}
+	if code := <-redelivery; code != http.StatusOK {
+		t.Errorf("redelivery should succeed, got %v", code)
+	}
+	if calls != 2 {
+		t.Errorf("redelivery should be processed after failed first attempt, got %v calls", calls)
+	}
+}
+
+func TestFileDeduplicationStoreCompactsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup")
+	store, err := webhooks.NewFileDeduplicationStore(path, time.Minute)
+	if err != nil {
+		t.Fatalf("couldn't create store: %v", err)
+	}
+	defer store.Close()
+	store.Reserve("kept")
+	for i := 0; i < webhooks.CompactionThreshold; i++ {
+		key := fmt.Sprintf("key_%v", i)
+		store.Reserve(key)
+		store.Release(key)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("couldn't read file: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines > webhooks.CompactionThreshold+1 {
+		t.Errorf("file should be compacted, got %v records", lines)
+	}
+	if first, _ := store.Reserve("kept"); first {
+		t.Error("kept key should survive compaction")
+	}
+}
@@ -0,0 +1,178 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrChatQueueFull is returned when webhook cannot be queued, because its chat's queue
+// has already reached the limit defined in ChatOrderingOptions.
+var ErrChatQueueFull = errors.New("chat queue is full")
+
+// ChatOrderingOptions configures ordered dispatch of webhooks, see Configuration.WithChatOrdering.
+type ChatOrderingOptions struct {
+	// QueueSize is the maximum number of webhooks waiting for processing in a single chat.
+	// Defaults to 100.
+	QueueSize int
+	// IdleTimeout is the time after which idle chat queue is released.
+	// Defaults to 1 minute.
+	IdleTimeout time.Duration
+}
+
+type chatDispatcher struct {
+	queueSize   int
+	idleTimeout time.Duration
+
+	mu     sync.Mutex
+	queues map[string]chan *dispatchJob
+}
+
+// States of dispatchJob, changed atomically by the dispatcher and the chat worker.
+const (
+	jobQueued int32 = iota
+	jobRunning
+	jobCancelled
+)
+
+type dispatchJob struct {
+	ctx    context.Context
+	wh     *Webhook
+	handle Handler
+	done   chan error
+	state  int32
+}
+
+func newChatDispatcher(opts *ChatOrderingOptions) *chatDispatcher {
+	d := &chatDispatcher{
+		queueSize:   100,
+		idleTimeout: time.Minute,
+		queues:      make(map[string]chan *dispatchJob),
+	}
+	if opts != nil {
+		if opts.QueueSize > 0 {
+			d.queueSize = opts.QueueSize
+		}
+		if opts.IdleTimeout > 0 {
+			d.idleTimeout = opts.IdleTimeout
+		}
+	}
+	return d
+}
+
+// dispatch runs handler for given webhook after all previously dispatched webhooks
+// of the same chat are processed. Webhooks not related to any chat are handled immediately.
+//
+// If ctx is done while the webhook is still queued, it's taken off the queue and ctx.Err() is returned,
+// so the handler is never called for it. Once the handler is running, dispatch waits for its result.
+func (d *chatDispatcher) dispatch(ctx context.Context, wh *Webhook, handle Handler) error {
+	chatID := wh.ChatID()
+	if chatID == "" {
+		return handle(ctx, wh)
+	}
+
+	job := &dispatchJob{
+		ctx:    ctx,
+		wh:     wh,
+		handle: handle,
+		done:   make(chan error, 1),
+	}
+
+	d.mu.Lock()
+	queue, exists := d.queues[chatID]
+	if !exists {
+		queue = make(chan *dispatchJob, d.queueSize)
+		d.queues[chatID] = queue
+		go d.work(chatID, queue)
+	}
+	select {
+	case queue <- job:
+	default:
+		d.mu.Unlock()
+		return ErrChatQueueFull
+	}
+	d.mu.Unlock()
+
+	select {
+	case err := <-job.done:
+		return err
+	case <-ctx.Done():
+		if atomic.CompareAndSwapInt32(&job.state, jobQueued, jobCancelled) {
+			return ctx.Err()
+		}
+		return <-job.done
+	}
+}
+
+func (d *chatDispatcher) work(chatID string, queue chan *dispatchJob) {
+	timer := time.NewTimer(d.idleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case job := <-queue:
+			if atomic.CompareAndSwapInt32(&job.state, jobQueued, jobRunning) {
+				job.done <- job.handle(job.ctx, job.wh)
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(d.idleTimeout)
+		case <-timer.C:
+			d.mu.Lock()
+			if len(queue) > 0 {
+				d.mu.Unlock()
+				timer.Reset(d.idleTimeout)
+				continue
+			}
+			delete(d.queues, chatID)
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+// ChatID returns ID of the chat that given webhook relates to.
+// It returns an empty string for webhooks not related to any chat or with payload not decoded yet.
+func (wh *Webhook) ChatID() string {
+	switch p := wh.Payload.(type) {
+	case *IncomingChat:
+		return p.Chat.ID
+	case *IncomingEvent:
+		return p.ChatID
+	case *EventUpdated:
+		return p.ChatID
+	case *IncomingRichMessagePostback:
+		return p.ChatID
+	case *ChatDeactivated:
+		return p.ChatID
+	case *ChatPropertiesUpdated:
+		return p.ChatID
+	case *ThreadPropertiesUpdated:
+		return p.ChatID
+	case *ChatPropertiesDeleted:
+		return p.ChatID
+	case *ThreadPropertiesDeleted:
+		return p.ChatID
+	case *UserAddedToChat:
+		return p.ChatID
+	case *UserRemovedFromChat:
+		return p.ChatID
+	case *ThreadTagged:
+		return p.ChatID
+	case *ThreadUntagged:
+		return p.ChatID
+	case *EventsMarkedAsSeen:
+		return p.ChatID
+	case *ChatAccessUpdated:
+		return p.ID
+	case *EventPropertiesUpdated:
+		return p.ChatID
+	case *EventPropertiesDeleted:
+		return p.ChatID
+	case *ChatTransferred:
+		return p.ChatID
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+func chatWebhookBody(action, chatID string) []byte {
+	return []byte(fmt.Sprintf(`{"webhook_id":"wh","action":%q,"payload":{"chat_id":%q}}`, action, chatID))
+}
+
+func TestChatOrderingProcessesWebhooksOfSameChatSequentially(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+	var order []string
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		order = append(order, wh.Action)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().
+		WithAction("incoming_event", handler, "").
+		WithAction("chat_deactivated", handler, "").
+		WithChatOrdering(nil)
+	h := webhooks.NewWebhookHandler(cfg)
+
+	var wg sync.WaitGroup
+	for _, action := range []string{"incoming_event", "incoming_event", "chat_deactivated"} {
+		wg.Add(1)
+		go func(action string) {
+			defer wg.Done()
+			resp := httptest.NewRecorder()
+			h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(chatWebhookBody(action, "chat_1"))))
+			if resp.Code != http.StatusOK {
+				t.Errorf("invalid code: %v", resp.Code)
+			}
+		}(action)
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+
+	if maxActive != 1 {
+		t.Errorf("webhooks of the same chat processed concurrently: %v", maxActive)
+	}
+	if len(order) != 3 || order[2] != "chat_deactivated" {
+		t.Errorf("invalid processing order: %v", order)
+	}
+}
+
+func TestChatOrderingProcessesDifferentChatsInParallel(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan string, 2)
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		started <- wh.ChatID()
+		<-release
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().WithAction("incoming_event", handler, "").WithChatOrdering(nil)
+	h := webhooks.NewWebhookHandler(cfg)
+
+	var wg sync.WaitGroup
+	for _, chatID := range []string{"chat_1", "chat_2"} {
+		wg.Add(1)
+		go func(chatID string) {
+			defer wg.Done()
+			h(httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(chatWebhookBody("incoming_event", chatID))))
+		}(chatID)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("webhooks of different chats were not processed in parallel")
+		}
+	}
+	close(release)
+	wg.Wait()
+}
+
+func TestChatOrderingRejectsWebhooksWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().
+		WithAction("incoming_event", handler, "").
+		WithChatOrdering(&webhooks.ChatOrderingOptions{QueueSize: 1})
+	h := webhooks.NewWebhookHandler(cfg)
+	send := func() int {
+		resp := httptest.NewRecorder()
+		h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(chatWebhookBody("incoming_event", "chat_1"))))
+		return resp.Code
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); send() }()
+	<-started
+	go func() { defer wg.Done(); send() }()
+	time.Sleep(10 * time.Millisecond)
+
+	if code := send(); code != http.StatusServiceUnavailable {
+		t.Errorf("invalid code: %v", code)
+	}
+	close(release)
+	wg.Wait()
+}
+
+func TestChatOrderingDropsWebhooksCancelledInQueue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var mu sync.Mutex
+	var handled []string
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		mu.Lock()
+		handled = append(handled, wh.Action)
+		mu.Unlock()
+		if wh.Action == "incoming_event" {
+			close(started)
+			<-release
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().
+		WithAction("incoming_event", handler, "").
+		WithAction("chat_deactivated", handler, "").
+		WithChatOrdering(nil)
+	h := webhooks.NewWebhookHandler(cfg)
+
+	slow := make(chan int, 1)
+	go func() {
+		resp := httptest.NewRecorder()
+		h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(chatWebhookBody("incoming_event", "chat_1"))))
+		slow <- resp.Code
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(chatWebhookBody("chat_deactivated", "chat_1")))
+	h(resp, req.WithContext(ctx))
+	if resp.Code != http.StatusInternalServerError {
+		t.Errorf("invalid code of cancelled webhook: %v", resp.Code)
+	}
+
+	close(release)
+	if code := <-slow; code != http.StatusOK {
+		t.Errorf("invalid code: %v", code)
+	}
+	// Queued webhook is skipped by the chat worker right after the slow one.
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 1 || handled[0] != "incoming_event" {
+		t.Errorf("cancelled webhook shouldn't be handled: %v", handled)
+	}
+}
+
+func TestChatOrderingReleasesIdleQueues(t *testing.T) {
+	calls := 0
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		calls++
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().
+		WithAction("incoming_event", handler, "").
+		WithChatOrdering(&webhooks.ChatOrderingOptions{IdleTimeout: time.Millisecond})
+	h := webhooks.NewWebhookHandler(cfg)
+
+	for i := 0; i < 2; i++ {
+		resp := httptest.NewRecorder()
+		h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(chatWebhookBody("incoming_event", "chat_1"))))
+		if resp.Code != http.StatusOK {
+			t.Errorf("invalid code: %v", resp.Code)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if calls != 2 {
+		t.Errorf("invalid number of handler calls: %v", calls)
+	}
+}
@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorCode identifies the reason of webhook processing failure.
+type ErrorCode string
+
+// Possible values of ErrorCode.
+const (
+	// Client errors - the request itself is invalid.
+	ErrCodeMethodNotAllowed       ErrorCode = "method_not_allowed"
+	ErrCodeUnsupportedContentType ErrorCode = "unsupported_content_type"
+	ErrCodeBodyTooLarge           ErrorCode = "body_too_large"
+	ErrCodeUnsupportedAction      ErrorCode = "unsupported_action"
+	ErrCodeUnknownAction          ErrorCode = "unknown_action"
+	ErrCodeInvalidSecretKey       ErrorCode = "invalid_secret_key"
+
+	// Decoding errors - the request couldn't be decoded. They're not considered client errors, as they're
+	// responded with 500 (so that LiveChat retries them), eg. in case of payload not matching outdated structures.
+	ErrCodeMalformedWebhook ErrorCode = "malformed_webhook"
+	ErrCodeMalformedPayload ErrorCode = "malformed_payload"
+
+	// Server errors - the request is valid, but couldn't be processed.
+	ErrCodeReadBody          ErrorCode = "read_body"
+	ErrCodeSecretResolution  ErrorCode = "secret_resolution"
+	ErrCodeDeduplication     ErrorCode = "deduplication"
+	ErrCodeQueueFull         ErrorCode = "queue_full"
+	ErrCodeHandler           ErrorCode = "handler"
+	ErrCodeDeadLetterStorage ErrorCode = "dead_letter_storage"
+)
+
+var statusCodes = map[ErrorCode]int{
+	ErrCodeMethodNotAllowed:       http.StatusMethodNotAllowed,
+	ErrCodeUnsupportedContentType: http.StatusUnsupportedMediaType,
+	ErrCodeBodyTooLarge:           http.StatusRequestEntityTooLarge,
+	ErrCodeMalformedWebhook:       http.StatusInternalServerError,
+	ErrCodeMalformedPayload:       http.StatusInternalServerError,
+	ErrCodeUnsupportedAction:      http.StatusBadRequest,
+	ErrCodeUnknownAction:          http.StatusBadRequest,
+	ErrCodeInvalidSecretKey:       http.StatusBadRequest,
+	ErrCodeReadBody:               http.StatusInternalServerError,
+	ErrCodeSecretResolution:       http.StatusInternalServerError,
+	ErrCodeDeduplication:          http.StatusInternalServerError,
+	ErrCodeQueueFull:              http.StatusServiceUnavailable,
+	ErrCodeHandler:                http.StatusInternalServerError,
+	ErrCodeDeadLetterStorage:      http.StatusInternalServerError,
+}
+
+// IsClientError reports whether the code describes invalid request, which shouldn't be retried.
+func (c ErrorCode) IsClientError() bool {
+	switch c {
+	case ErrCodeMethodNotAllowed, ErrCodeUnsupportedContentType, ErrCodeBodyTooLarge, ErrCodeUnsupportedAction,
+		ErrCodeUnknownAction, ErrCodeInvalidSecretKey:
+		return true
+	}
+	return false
+}
+
+// Error represents webhook processing failure passed to StructuredErrorHandler.
+type Error struct {
+	Code ErrorCode
+	// StatusCode is HTTP status code WebhookHandler responds with by default.
+	StatusCode int
+	// Action is webhook's action, if it was decoded before the failure.
+	Action string
+	Err    error
+
+	// handlerFailed is set if Handler was called and failed, even if Code describes a later failure.
+	handlerFailed bool
+}
+
+func newError(code ErrorCode, err error) *Error {
+	return &Error{
+		Code:       code,
+		StatusCode: statusCodes[code],
+		Err:        err,
+	}
+}
+
+func (e *Error) withAction(action string) *Error {
+	e.Action = action
+	return e
+}
+
+func (e *Error) withHandlerFailed() *Error {
+	e.handlerFailed = true
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns underlying error, eg. error returned by Handler.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsClientError reports whether webhook processing failed due to invalid request.
+func (e *Error) IsClientError() bool {
+	return e.Code.IsClientError()
+}
+
+// ErrorCodeOf returns ErrorCode of given error, or an empty string if it's not webhook processing Error.
+func ErrorCodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
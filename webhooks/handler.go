@@ -2,10 +2,17 @@ package webhooks
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/metrics"
 )
 
 // The ErrorHandler type is used to define custom error handlers for WebhookHandler.
@@ -14,17 +21,47 @@ import (
 // eg. to always respond with 200OK.
 type ErrorHandler func(w http.ResponseWriter, err string, statusCode int)
 
+// The StructuredErrorHandler type is used to define custom error handlers for WebhookHandler,
+// which need to distinguish between reasons of webhook processing failure (see ErrorCode).
+type StructuredErrorHandler func(w http.ResponseWriter, err *Error)
+
 // A Configuration structure is used to configure WebhookHandler
+//
+// Actions, fallback action and SecretResolver can be changed while WebhookHandler is running
+// (see Configuration.Update). Other options must be set before WebhookHandler is created.
 type Configuration struct {
-	actions     map[string]*actionConfiguration
-	handleError ErrorHandler
+	mu          sync.RWMutex
+	actions     map[configuration.WebhookAction]*actionConfiguration
+	additional  map[configuration.WebhookAction][]string
+	fallback    *actionConfiguration
+	secrets     SecretResolver
+	payloads    *PayloadRegistry
+	handleError StructuredErrorHandler
+	maxBodySize int64
+	strict      bool
+	dispatcher  *chatDispatcher
+	dedupStore  DeduplicationStore
+	dedupKey    DeduplicationKeyFunc
+	dedupLocks  *keyLocks
+	deadLetters DeadLetterSink
+	statsSink   StatsSinkFunc
 }
 
 type actionConfiguration struct {
 	secretKey string
 	handle    Handler
+	raw       bool
 }
 
+// StatsSinkFunc is called after each webhook received by WebhookHandler with statistics of its processing.
+type StatsSinkFunc func(stats metrics.WebhookStats)
+
+// The SecretResolver type is used to look up secret keys of webhooks, eg. per WebhookID or OrganizationID.
+//
+// It receives webhook with Payload not decoded yet. More than one secret key might be returned,
+// eg. during secret rotation - webhook is accepted if its secret key matches any of them.
+type SecretResolver func(ctx context.Context, wh *Webhook) ([]string, error)
+
 // The Handler type is used to define webhook processors.
 //
 // It can be used with WebhookHandler, in which case WebhookHandler will
@@ -36,8 +73,12 @@ type Handler func(context.Context, *Webhook) error
 // errors.
 func NewConfiguration() *Configuration {
 	return &Configuration{
-		actions:     make(map[string]*actionConfiguration),
-		handleError: http.Error,
+		actions:    make(map[configuration.WebhookAction]*actionConfiguration),
+		additional: make(map[configuration.WebhookAction][]string),
+		payloads:   NewPayloadRegistry(),
+		handleError: func(w http.ResponseWriter, err *Error) {
+			http.Error(w, err.Error(), err.StatusCode)
+		},
 	}
 }
 
@@ -46,139 +87,436 @@ func NewConfiguration() *Configuration {
 // If secretKey is an empty string, then no validation of webhook's secret is performed.
 // Otherwise, webhook's secret is strictly validated. In case of any mismatch between expected and actual secret key,
 // webhook processing is stopped and error is returned.
-func (cfg *Configuration) WithAction(action string, handler Handler, secretKey string) *Configuration {
+func (cfg *Configuration) WithAction(action configuration.WebhookAction, handler Handler, secretKey string) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.actions[action] = &actionConfiguration{
+		handle:    handler,
+		secretKey: secretKey,
+	}
+	return cfg
+}
+
+// WithAdditionalData declares additional data requested for webhooks of given action (see configuration.Webhook's AdditionalData),
+// eg. configuration.ChatPresenceUserIDsData. The declaration is independent of Handler attached to the action,
+// so it can be made before or after the Handler is attached and it's kept when the Handler is replaced or removed.
+//
+// Declared additional data is considered available in Webhook's Additional, even if LiveChat omits it because it's empty.
+// Additional data not declared is available only if it's present in webhook.
+func (cfg *Configuration) WithAdditionalData(action configuration.WebhookAction, names ...string) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.additional[action] = names
+	return cfg
+}
+
+// WithRawAction allows to attach custom webhook Handler for given webhook action, which receives
+// webhook's RawPayload without decoding it (ie. Payload is nil).
+//
+// It can be used to handle webhook actions not supported by this package yet. Secret key is validated as in WithAction.
+func (cfg *Configuration) WithRawAction(action configuration.WebhookAction, handler Handler, secretKey string) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
 	cfg.actions[action] = &actionConfiguration{
 		handle:    handler,
 		secretKey: secretKey,
+		raw:       true,
 	}
 	return cfg
 }
 
+// WithFallbackAction allows to attach custom webhook Handler for all webhook actions without their own Handler.
+// The Handler receives webhook's RawPayload without decoding it (ie. Payload is nil).
+//
+// Without fallback Handler, such webhooks are rejected. Secret key is validated as in WithAction.
+func (cfg *Configuration) WithFallbackAction(handler Handler, secretKey string) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.fallback = &actionConfiguration{
+		handle:    handler,
+		secretKey: secretKey,
+		raw:       true,
+	}
+	return cfg
+}
+
+// RemoveAction detaches Handler of given webhook action. Webhooks with the action are handled by
+// the fallback Handler, if it's attached, or rejected otherwise.
+func (cfg *Configuration) RemoveAction(action configuration.WebhookAction) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	delete(cfg.actions, action)
+	return cfg
+}
+
+// WithSecretKey allows to replace secret key of given webhook action, eg. during secret rotation.
+// It must be called after Handler for the action is attached.
+func (cfg *Configuration) WithSecretKey(action configuration.WebhookAction, secretKey string) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if acfg, exists := cfg.actions[action]; exists {
+		updated := *acfg
+		updated.secretKey = secretKey
+		cfg.actions[action] = &updated
+	}
+	return cfg
+}
+
+// Update allows to change actions, fallback action, SecretResolver and payload structures atomically,
+// while WebhookHandler is running.
+//
+// The update function receives a copy of Configuration, on which it should call WithAction, RemoveAction,
+// WithSecretKey, WithAdditionalData, WithRawAction, WithFallbackAction, WithSecretResolver, WithPayloadType
+// or WithPayloadRegistry. Once it returns, all changes are applied at once - webhooks are processed either
+// with the previous or with the updated configuration. Changes of other options (eg. WithMaxBodySize) made on
+// the copy are discarded. The update function mustn't use the original Configuration.
+func (cfg *Configuration) Update(update func(cfg *Configuration)) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	staged := &Configuration{
+		actions:    make(map[configuration.WebhookAction]*actionConfiguration, len(cfg.actions)),
+		additional: make(map[configuration.WebhookAction][]string, len(cfg.additional)),
+		fallback:   cfg.fallback,
+		secrets:    cfg.secrets,
+		payloads:   cfg.payloads.clone(),
+	}
+	for action, acfg := range cfg.actions {
+		staged.actions[action] = acfg
+	}
+	for action, names := range cfg.additional {
+		staged.additional[action] = names
+	}
+	update(staged)
+	cfg.actions = staged.actions
+	cfg.additional = staged.additional
+	cfg.fallback = staged.fallback
+	cfg.secrets = staged.secrets
+	cfg.payloads = staged.payloads
+}
+
+// WithPayloadRegistry allows to replace PayloadRegistry used to decode webhook payloads,
+// eg. with one containing payload structures for webhook actions not supported by this package yet.
+func (cfg *Configuration) WithPayloadRegistry(r *PayloadRegistry) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.payloads = r
+	return cfg
+}
+
+// WithPayloadType registers payload structure for given webhook action in Configuration's PayloadRegistry.
+func (cfg *Configuration) WithPayloadType(action configuration.WebhookAction, factory PayloadFactory) *Configuration {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	cfg.payloads.Register(action, factory)
+	return cfg
+}
+
+// WithSecretResolver allows to attach SecretResolver used to validate secret keys of all webhooks.
+//
+// Secret keys returned by SecretResolver are accepted in addition to secretKey passed to WithAction.
+// If neither of them provides any secret key, webhook is rejected.
+func (cfg *Configuration) WithSecretResolver(r SecretResolver) *Configuration {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.secrets = r
+	return cfg
+}
+
 // WithErrorHandler allows to attach custom ErrorHandler, which acts as sink for all WebhookHandler errors.
 //
 // Custom ErrorHandler might be used to eg. always return 200OK for incoming webhooks.
 func (cfg *Configuration) WithErrorHandler(h ErrorHandler) *Configuration {
+	cfg.handleError = func(w http.ResponseWriter, err *Error) {
+		h(w, err.Error(), err.StatusCode)
+	}
+	return cfg
+}
+
+// WithStructuredErrorHandler allows to attach custom StructuredErrorHandler, which acts as sink for all WebhookHandler errors.
+//
+// It replaces ErrorHandler attached with WithErrorHandler.
+func (cfg *Configuration) WithStructuredErrorHandler(h StructuredErrorHandler) *Configuration {
 	cfg.handleError = h
 	return cfg
 }
 
+// WithMaxBodySize limits size of webhook body accepted by WebhookHandler to given number of bytes.
+// Larger webhooks are rejected with ErrCodeBodyTooLarge. By default, size of webhook body is not limited.
+func (cfg *Configuration) WithMaxBodySize(n int64) *Configuration {
+	cfg.maxBodySize = n
+	return cfg
+}
+
+// WithStrictRequests makes WebhookHandler accept only POST requests with JSON content type,
+// as sent by LiveChat. Other requests are rejected with ErrCodeMethodNotAllowed or ErrCodeUnsupportedContentType.
+func (cfg *Configuration) WithStrictRequests() *Configuration {
+	cfg.strict = true
+	return cfg
+}
+
+// WithStatsSink allows to set a statistics sink that receives metrics of every webhook received by WebhookHandler,
+// eg. to count webhooks per action or to alert on secret mismatches and Handler errors.
+//
+// When WithChatOrdering is used, Handler execution time includes time spent waiting in chat's queue.
+func (cfg *Configuration) WithStatsSink(f StatsSinkFunc) *Configuration {
+	cfg.statsSink = f
+	return cfg
+}
+
+// WithChatOrdering makes WebhookHandler process webhooks of the same chat one by one,
+// in order of their arrival. Webhooks of different chats are still processed in parallel.
+//
+// Webhooks are queued per chat ID (see Webhook.ChatID). When chat's queue is full,
+// webhook is rejected with ErrChatQueueFull. Webhooks not related to any chat are not queued.
+// Webhooks whose request is cancelled while they're queued are dropped without calling their Handler.
+func (cfg *Configuration) WithChatOrdering(opts *ChatOrderingOptions) *Configuration {
+	cfg.dispatcher = newChatDispatcher(opts)
+	return cfg
+}
+
+// WithDeduplication makes WebhookHandler skip webhooks that were already processed, eg. redelivered
+// by LiveChat after a timeout. Skipped webhooks are responded with 200OK without calling Handler.
+//
+// Webhooks are identified by key computed with given DeduplicationKeyFunc. If it's nil,
+// DefaultDeduplicationKey is used. If Handler returns an error, webhook's key is released from
+// the store, so that redelivered webhook is processed again. If releasing fails, the webhook
+// is reported with ErrCodeDeduplication instead of ErrCodeHandler, as its redelivery may be skipped.
+// Redelivered webhook received while the first attempt is still processed waits for its result.
+func (cfg *Configuration) WithDeduplication(store DeduplicationStore, key DeduplicationKeyFunc) *Configuration {
+	if key == nil {
+		key = DefaultDeduplicationKey
+	}
+	cfg.dedupStore = store
+	cfg.dedupKey = key
+	cfg.dedupLocks = newKeyLocks()
+	return cfg
+}
+
 // NewWebhookHandler creates WebhookHandler that can be used with golang HTTP server.
 //
 // WebhookHandler decodes raw webhook JSON into dedicated webhook structures and, if provided, passes
 // those structures into webhook Handlers attached to given webhook type.
 func NewWebhookHandler(cfg *Configuration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
+		var stats metrics.WebhookStats
+		err := cfg.serve(w, r, &stats)
+		if cfg.statsSink != nil {
+			cfg.statsSink(webhookStats(stats, err))
+		}
 		if err != nil {
-			cfg.handleError(w, fmt.Sprintf("couldn't read request body: %v", err), http.StatusInternalServerError)
+			cfg.handleError(w, err)
 			return
 		}
 
-		var wh Webhook
-		if err := json.Unmarshal(body, &wh); err != nil {
-			cfg.handleError(w, fmt.Sprintf("couldn't unmarshal webhook base: %v", err), http.StatusInternalServerError)
-			return
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (cfg *Configuration) serve(w http.ResponseWriter, r *http.Request, stats *metrics.WebhookStats) *Error {
+	if err := cfg.validateRequest(r); err != nil {
+		return err
+	}
+
+	body, err := cfg.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.process(r.Context(), body, stats); err != nil {
+		if err.handlerFailed && cfg.deadLetters != nil {
+			if dlErr := cfg.deadLetters.Put(newDeadLetter(err.Action, body, err.Error())); dlErr != nil {
+				err = newError(ErrCodeDeadLetterStorage, fmt.Errorf("%v (couldn't store dead letter: %v)", err, dlErr)).withAction(err.Action).withHandlerFailed()
+			}
 		}
-		acfg, exists := cfg.actions[wh.Action]
-		if !exists {
-			cfg.handleError(w, fmt.Sprintf("Unsupported action: %v", wh.Action), http.StatusBadRequest)
-			return
+		return err
+	}
+	return nil
+}
+
+func webhookStats(stats metrics.WebhookStats, err *Error) metrics.WebhookStats {
+	if err == nil {
+		stats.Success = true
+		return stats
+	}
+	if stats.Action == "" {
+		stats.Action = err.Action
+	}
+	stats.ErrorCode = string(err.Code)
+	switch err.Code {
+	case ErrCodeMalformedWebhook, ErrCodeMalformedPayload:
+		stats.DecodeFailed = true
+	case ErrCodeInvalidSecretKey:
+		stats.SecretMismatch = true
+	}
+	stats.HandlerFailed = err.handlerFailed
+	return stats
+}
+
+func (cfg *Configuration) validateRequest(r *http.Request) *Error {
+	if !cfg.strict {
+		return nil
+	}
+	if r.Method != http.MethodPost {
+		return newError(ErrCodeMethodNotAllowed, fmt.Errorf("method not allowed: %v", r.Method))
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return newError(ErrCodeUnsupportedContentType, fmt.Errorf("unsupported content type: %v", r.Header.Get("Content-Type")))
+	}
+	return nil
+}
+
+func (cfg *Configuration) readBody(r *http.Request) ([]byte, *Error) {
+	if cfg.maxBodySize <= 0 {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, newError(ErrCodeReadBody, fmt.Errorf("couldn't read request body: %v", err))
 		}
-		if acfg.secretKey != "" && wh.SecretKey != acfg.secretKey {
-			cfg.handleError(w, "Invalid webhook secret key", http.StatusBadRequest)
-			return
+		return body, nil
+	}
+
+	if r.ContentLength > cfg.maxBodySize {
+		return nil, newError(ErrCodeBodyTooLarge, fmt.Errorf("request body too large: %v bytes", r.ContentLength))
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, cfg.maxBodySize+1))
+	if err != nil {
+		return nil, newError(ErrCodeReadBody, fmt.Errorf("couldn't read request body: %v", err))
+	}
+	if int64(len(body)) > cfg.maxBodySize {
+		return nil, newError(ErrCodeBodyTooLarge, fmt.Errorf("request body too large: more than %v bytes", cfg.maxBodySize))
+	}
+	return body, nil
+}
+
+// process decodes given webhook body and passes it to Handler attached to webhook's action.
+// Payload is decoded only once webhook's secret key is validated.
+//
+// Statistics of the processing are collected in given stats.
+func (cfg *Configuration) process(ctx context.Context, body []byte, stats *metrics.WebhookStats) *Error {
+	wh, derr := decodeWebhook(body)
+	if derr != nil {
+		return derr
+	}
+	stats.Action = wh.Action
+	snapshot := cfg.action(wh.Action)
+	acfg := snapshot.acfg
+	if acfg == nil {
+		return newError(ErrCodeUnsupportedAction, fmt.Errorf("Unsupported action: %v", wh.Action)).withAction(wh.Action)
+	}
+	valid, err := validateSecretKey(ctx, wh, acfg, snapshot.secrets)
+	if err != nil {
+		return newError(ErrCodeSecretResolution, fmt.Errorf("couldn't resolve webhook secret keys: %v", err)).withAction(wh.Action)
+	}
+	if !valid {
+		return newError(ErrCodeInvalidSecretKey, errors.New("Invalid webhook secret key")).withAction(wh.Action)
+	}
+
+	if !acfg.raw {
+		payload := snapshot.payloads.New(configuration.WebhookAction(wh.Action))
+		if payload == nil {
+			return newError(ErrCodeUnknownAction, fmt.Errorf("unknown webhook: %v", wh.Action)).withAction(wh.Action)
+		}
+		if derr := decodePayload(wh, payload); derr != nil {
+			return derr
 		}
+	}
 
-		var payload interface{}
-		switch wh.Action {
-		case "incoming_chat":
-			payload = &IncomingChat{}
-		case "incoming_event":
-			payload = &IncomingEvent{}
-		case "event_updated":
-			payload = &EventUpdated{}
-		case "incoming_rich_message_postback":
-			payload = &IncomingRichMessagePostback{}
-		case "chat_deactivated":
-			payload = &ChatDeactivated{}
-		case "chat_properties_updated":
-			payload = &ChatPropertiesUpdated{}
-		case "thread_properties_updated":
-			payload = &ThreadPropertiesUpdated{}
-		case "chat_properties_deleted":
-			payload = &ChatPropertiesDeleted{}
-		case "thread_properties_deleted":
-			payload = &ThreadPropertiesDeleted{}
-		case "user_added_to_chat":
-			payload = &UserAddedToChat{}
-		case "user_removed_from_chat":
-			payload = &UserRemovedFromChat{}
-		case "thread_tagged":
-			payload = &ThreadTagged{}
-		case "thread_untagged":
-			payload = &ThreadUntagged{}
-		case "agent_created":
-			payload = &AgentCreated{}
-		case "agent_updated":
-			payload = &AgentUpdated{}
-		case "agent_deleted":
-			payload = &AgentDeleted{}
-		case "agent_suspended":
-			payload = &AgentSuspended{}
-		case "agent_unsuspended":
-			payload = &AgentUnsuspended{}
-		case "agent_approved":
-			payload = &AgentApproved{}
-		case "events_marked_as_seen":
-			payload = &EventsMarkedAsSeen{}
-		case "chat_access_updated":
-			payload = &ChatAccessUpdated{}
-		case "event_properties_updated":
-			payload = &EventPropertiesUpdated{}
-		case "event_properties_deleted":
-			payload = &EventPropertiesDeleted{}
-		case "routing_status_set":
-			payload = &RoutingStatusSet{}
-		case "chat_transferred":
-			payload = &ChatTransferred{}
-		case "incoming_customer":
-			payload = &IncomingCustomer{}
-		case "customer_session_fields_updated":
-			payload = &CustomerSessionFieldsUpdated{}
-		case "group_created":
-			payload = &GroupCreated{}
-		case "group_updated":
-			payload = &GroupUpdated{}
-		case "group_deleted":
-			payload = &GroupDeleted{}
-		case "auto_access_added":
-			payload = &AutoAccessAdded{}
-		case "auto_access_updated":
-			payload = &AutoAccessUpdated{}
-		case "auto_access_deleted":
-			payload = &AutoAccessDeleted{}
-		case "bot_created":
-			payload = &BotCreated{}
-		case "bot_updated":
-			payload = &BotUpdated{}
-		case "bot_deleted":
-			payload = &BotDeleted{}
-		default:
-			cfg.handleError(w, fmt.Sprintf("unknown webhook: %v", wh.Action), http.StatusBadRequest)
-			return
+	additional, err := decodeAdditionalData(wh.AdditionalData, snapshot.additional)
+	if err != nil {
+		return newError(ErrCodeMalformedWebhook, err).withAction(wh.Action)
+	}
+	wh.Additional = additional
+
+	var dedupKey string
+	if cfg.dedupStore != nil {
+		dedupKey = cfg.dedupKey(wh)
+		unlock, err := cfg.dedupLocks.lock(ctx, dedupKey)
+		if err != nil {
+			return newError(ErrCodeDeduplication, fmt.Errorf("couldn't wait for processing of webhook duplicate: %v", err)).withAction(wh.Action)
+		}
+		defer unlock()
+		first, err := cfg.dedupStore.Reserve(dedupKey)
+		if err != nil {
+			return newError(ErrCodeDeduplication, fmt.Errorf("couldn't check webhook duplication: %v", err)).withAction(wh.Action)
 		}
+		if !first {
+			return nil
+		}
+	}
 
-		if err := json.Unmarshal(wh.RawPayload, payload); err != nil {
-			cfg.handleError(w, fmt.Sprintf("couldn't unmarshal webhook payload: %v", err), http.StatusInternalServerError)
-			return
+	start := time.Now()
+	if cfg.dispatcher != nil {
+		err = cfg.dispatcher.dispatch(ctx, wh, acfg.handle)
+	} else {
+		err = acfg.handle(ctx, wh)
+	}
+	stats.HandlerExecutionTime = time.Since(start)
+	if err == nil {
+		return nil
+	}
+
+	var werr *Error
+	if errors.Is(err, ErrChatQueueFull) || errors.Is(err, ErrSubscriptionFull) || errors.Is(err, ErrSubscriptionClosed) {
+		werr = newError(ErrCodeQueueFull, fmt.Errorf("couldn't queue webhook: %w", err)).withAction(wh.Action)
+	} else {
+		werr = newError(ErrCodeHandler, fmt.Errorf("webhook handler error: %w", err)).withAction(wh.Action).withHandlerFailed()
+	}
+	if cfg.dedupStore != nil {
+		// Key left reserved would make redelivery of the webhook skipped as a duplicate.
+		if relErr := cfg.dedupStore.Release(dedupKey); relErr != nil {
+			werr.Code, werr.StatusCode = ErrCodeDeduplication, statusCodes[ErrCodeDeduplication]
+			werr.Err = fmt.Errorf("%w (couldn't release deduplication key, redelivery may be skipped: %v)", werr.Err, relErr)
 		}
-		wh.Payload = payload
+	}
+	return werr
+}
 
-		if err = acfg.handle(r.Context(), &wh); err != nil {
-			cfg.handleError(w, fmt.Sprintf("webhook handler error: %v", err), http.StatusInternalServerError)
-			return
+// actionSnapshot is a consistent snapshot of Configuration used to process single webhook.
+type actionSnapshot struct {
+	acfg       *actionConfiguration
+	secrets    SecretResolver
+	additional []string
+	payloads   *PayloadRegistry
+}
+
+// action returns snapshot of configuration of given webhook action (or fallback action), SecretResolver,
+// additional data declared for the action and PayloadRegistry to process webhook with.
+func (cfg *Configuration) action(action string) actionSnapshot {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	acfg, exists := cfg.actions[configuration.WebhookAction(action)]
+	if !exists {
+		acfg = cfg.fallback
+	}
+	return actionSnapshot{
+		acfg:       acfg,
+		secrets:    cfg.secrets,
+		additional: cfg.additional[configuration.WebhookAction(action)],
+		payloads:   cfg.payloads,
+	}
+}
+
+func validateSecretKey(ctx context.Context, wh *Webhook, acfg *actionConfiguration, resolver SecretResolver) (bool, error) {
+	var secrets []string
+	if acfg.secretKey != "" {
+		secrets = append(secrets, acfg.secretKey)
+	}
+	if resolver != nil {
+		resolved, err := resolver(ctx, wh)
+		if err != nil {
+			return false, err
 		}
+		secrets = append(secrets, resolved...)
+	} else if len(secrets) == 0 {
+		return true, nil
+	}
 
-		w.WriteHeader(http.StatusOK)
+	valid := 0
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		valid |= subtle.ConstantTimeCompare([]byte(wh.SecretKey), []byte(secret))
 	}
+	return valid == 1, nil
 }
@@ -3,6 +3,7 @@ package webhooks_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,10 +11,11 @@ import (
 	"os"
 	"testing"
 
+	"github.com/livechat/lc-sdk-go/v6/configuration"
 	"github.com/livechat/lc-sdk-go/v6/webhooks"
 )
 
-var verifiers = map[string]webhooks.Handler{
+var verifiers = map[configuration.WebhookAction]webhooks.Handler{
 	"incoming_chat":                   incomingChat,
 	"incoming_event":                  incomingEvent,
 	"event_updated":                   eventUpdated,
@@ -55,8 +57,8 @@ var verifiers = map[string]webhooks.Handler{
 func TestRejectWebhooksIfNoHandlersAreConnected(t *testing.T) {
 	cfg := webhooks.NewConfiguration()
 	h := webhooks.NewWebhookHandler(cfg)
-	action := "incoming_chat"
-	payload, err := os.ReadFile("./testdata/" + action + ".json")
+	action := configuration.IncomingChat
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
 	if err != nil {
 		t.Errorf("Missing test payload for action %v", action)
 		return
@@ -71,8 +73,8 @@ func TestRejectWebhooksIfNoHandlersAreConnected(t *testing.T) {
 }
 
 func TestRejectWebhooksIfFormatIsInvalid(t *testing.T) {
-	action := "incoming_chat"
-	payload, err := os.ReadFile("./testdata/" + action + ".json")
+	action := configuration.IncomingChat
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
 	if err != nil {
 		t.Errorf("Missing test payload for action %v", action)
 		return
@@ -90,8 +92,8 @@ func TestRejectWebhooksIfFormatIsInvalid(t *testing.T) {
 }
 
 func TestErrorHappensWithCustomErrorHandler(t *testing.T) {
-	action := "incoming_chat"
-	payload, err := os.ReadFile("./testdata/" + action + ".json")
+	action := configuration.IncomingChat
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
 	if err != nil {
 		t.Errorf("Missing test payload for action %v", action)
 		return
@@ -115,10 +117,10 @@ func TestErrorHappensWithCustomErrorHandler(t *testing.T) {
 
 func TestRejectWebhooksIfSecretKeyDoesntMatch(t *testing.T) {
 	verifier := func(context.Context, *webhooks.Webhook) error { return nil }
-	action := "incoming_chat"
+	action := configuration.IncomingChat
 	cfg := webhooks.NewConfiguration().WithAction(action, verifier, "other_dummy_key")
 	h := webhooks.NewWebhookHandler(cfg)
-	payload, err := os.ReadFile("./testdata/" + action + ".json")
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
 	if err != nil {
 		t.Errorf("Missing test payload for action %v", action)
 		return
@@ -143,10 +145,10 @@ func TestPayloadParsingOK(t *testing.T) {
 			return verifier(ctx, wh)
 		}
 	}
-	testAction := func(action string, verifier webhooks.Handler) error {
+	testAction := func(action configuration.WebhookAction, verifier webhooks.Handler) error {
 		cfg := webhooks.NewConfiguration().WithAction(action, withLicenseCheck(verifier), "dummy_key")
 		h := webhooks.NewWebhookHandler(cfg)
-		payload, err := os.ReadFile("./testdata/" + action + ".json")
+		payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
 		if err != nil {
 			return fmt.Errorf("Missing test payload for action %v", action)
 		}
@@ -160,7 +162,7 @@ func TestPayloadParsingOK(t *testing.T) {
 	}
 
 	for action, verifier := range verifiers {
-		t.Run(action, func(t *testing.T) {
+		t.Run(string(action), func(t *testing.T) {
 			stepError := testAction(action, verifier)
 			if stepError != nil {
 				t.Errorf("Payload incorrectly parsed for %v, error: %v", action, stepError)
@@ -183,10 +185,10 @@ func TestHandlerContextForwardsRequestContext(t *testing.T) {
 		}
 		return nil
 	}
-	action := "incoming_chat"
+	action := configuration.IncomingChat
 	cfg := webhooks.NewConfiguration().WithAction(action, verifier, "")
 	h := webhooks.NewWebhookHandler(cfg)
-	payload, err := os.ReadFile("./testdata/" + action + ".json")
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
 	if err != nil {
 		t.Errorf("Missing test payload for action %v", action)
 		return
@@ -200,3 +202,242 @@ func TestHandlerContextForwardsRequestContext(t *testing.T) {
 		return
 	}
 }
+
+func TestSecretResolverAcceptsAnyOfResolvedSecretKeys(t *testing.T) {
+	verifier := func(context.Context, *webhooks.Webhook) error { return nil }
+	action := configuration.IncomingChat
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Errorf("Missing test payload for action %v", action)
+		return
+	}
+
+	for _, tc := range []struct {
+		name         string
+		secrets      []string
+		err          error
+		expectedCode int
+	}{
+		{"rotated", []string{"new_dummy_key", "dummy_key"}, nil, http.StatusOK},
+		{"mismatch", []string{"new_dummy_key"}, nil, http.StatusBadRequest},
+		{"no secrets", nil, nil, http.StatusBadRequest},
+		{"resolver error", nil, errors.New("lookup failed"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := func(ctx context.Context, wh *webhooks.Webhook) ([]string, error) {
+				if wh.WebhookID == "" || wh.OrganizationID == "" {
+					t.Errorf("webhook base not decoded in resolver")
+				}
+				return tc.secrets, tc.err
+			}
+			cfg := webhooks.NewConfiguration().WithAction(action, verifier, "").WithSecretResolver(resolver)
+			h := webhooks.NewWebhookHandler(cfg)
+			req := httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload))
+			resp := httptest.NewRecorder()
+			h(resp, req)
+			if resp.Code != tc.expectedCode {
+				t.Errorf("invalid code: %v", resp.Code)
+			}
+		})
+	}
+}
+
+func TestStrictRequestsAreValidated(t *testing.T) {
+	verifier := func(context.Context, *webhooks.Webhook) error { return nil }
+	action := configuration.IncomingChat
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Errorf("Missing test payload for action %v", action)
+		return
+	}
+
+	for _, tc := range []struct {
+		name         string
+		method       string
+		contentType  string
+		expectedCode int
+	}{
+		{"valid", "POST", "application/json; charset=utf-8", http.StatusOK},
+		{"invalid method", "GET", "application/json", http.StatusMethodNotAllowed},
+		{"invalid content type", "POST", "text/plain", http.StatusUnsupportedMediaType},
+		{"missing content type", "POST", "", http.StatusUnsupportedMediaType},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := webhooks.NewConfiguration().WithAction(action, verifier, "").WithStrictRequests()
+			h := webhooks.NewWebhookHandler(cfg)
+			req := httptest.NewRequest(tc.method, "https://example.com", bytes.NewBuffer(payload))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			resp := httptest.NewRecorder()
+			h(resp, req)
+			if resp.Code != tc.expectedCode {
+				t.Errorf("invalid code: %v", resp.Code)
+			}
+		})
+	}
+}
+
+func TestRejectWebhooksExceedingMaxBodySize(t *testing.T) {
+	verifier := func(context.Context, *webhooks.Webhook) error { return nil }
+	action := configuration.IncomingChat
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Errorf("Missing test payload for action %v", action)
+		return
+	}
+
+	for _, tc := range []struct {
+		name          string
+		maxBodySize   int64
+		contentLength int64
+		expectedCode  int
+	}{
+		{"within limit", int64(len(payload)), int64(len(payload)), http.StatusOK},
+		{"declared too large", int64(len(payload)) - 1, int64(len(payload)), http.StatusRequestEntityTooLarge},
+		{"read too large", int64(len(payload)) - 1, -1, http.StatusRequestEntityTooLarge},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := webhooks.NewConfiguration().WithAction(action, verifier, "").WithMaxBodySize(tc.maxBodySize)
+			h := webhooks.NewWebhookHandler(cfg)
+			req := httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload))
+			req.ContentLength = tc.contentLength
+			resp := httptest.NewRecorder()
+			h(resp, req)
+			if resp.Code != tc.expectedCode {
+				t.Errorf("invalid code: %v", resp.Code)
+			}
+		})
+	}
+}
+
+func TestStructuredErrorHandlerReceivesErrorCodes(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+	action := configuration.IncomingChat
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Errorf("Missing test payload for action %v", action)
+		return
+	}
+
+	for _, tc := range []struct {
+		name         string
+		cfg          *webhooks.Configuration
+		body         []byte
+		expectedCode webhooks.ErrorCode
+		clientError  bool
+	}{
+		{
+			name:         "malformed webhook",
+			cfg:          webhooks.NewConfiguration(),
+			body:         append(append([]byte{}, payload...), '}'),
+			expectedCode: webhooks.ErrCodeMalformedWebhook,
+		},
+		{
+			name: "malformed payload",
+			cfg: webhooks.NewConfiguration().WithAction(action, func(context.Context, *webhooks.Webhook) error {
+				return nil
+			}, ""),
+			body:         []byte(`{"action": "incoming_chat", "payload": {"chat": []}}`),
+			expectedCode: webhooks.ErrCodeMalformedPayload,
+		},
+		{
+			name: "invalid secret key and malformed payload",
+			cfg: webhooks.NewConfiguration().WithAction(action, func(context.Context, *webhooks.Webhook) error {
+				return nil
+			}, "other_dummy_key"),
+			body:         []byte(`{"action": "incoming_chat", "secret_key": "dummy_key", "payload": {"chat": []}}`),
+			expectedCode: webhooks.ErrCodeInvalidSecretKey,
+			clientError:  true,
+		},
+		{
+			name:         "unsupported action",
+			cfg:          webhooks.NewConfiguration(),
+			body:         payload,
+			expectedCode: webhooks.ErrCodeUnsupportedAction,
+			clientError:  true,
+		},
+		{
+			name: "invalid secret key",
+			cfg: webhooks.NewConfiguration().WithAction(action, func(context.Context, *webhooks.Webhook) error {
+				return nil
+			}, "other_dummy_key"),
+			body:         payload,
+			expectedCode: webhooks.ErrCodeInvalidSecretKey,
+			clientError:  true,
+		},
+		{
+			name: "handler error",
+			cfg: webhooks.NewConfiguration().WithAction(action, func(context.Context, *webhooks.Webhook) error {
+				return handlerErr
+			}, ""),
+			body:         payload,
+			expectedCode: webhooks.ErrCodeHandler,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			h := webhooks.NewWebhookHandler(tc.cfg.WithStructuredErrorHandler(func(w http.ResponseWriter, err *webhooks.Error) {
+				called = true
+				if err.Code != tc.expectedCode {
+					t.Errorf("invalid error code: %v", err.Code)
+				}
+				if err.IsClientError() != tc.clientError {
+					t.Errorf("invalid client error classification: %v", err.IsClientError())
+				}
+				if err.Code == webhooks.ErrCodeHandler && !errors.Is(err, handlerErr) {
+					t.Errorf("handler error not wrapped: %v", err)
+				}
+			}))
+			req := httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(tc.body))
+			h(httptest.NewRecorder(), req)
+			if !called {
+				t.Error("error handler not called")
+			}
+		})
+	}
+}
+
+func TestRawPayloadIsPreservedAlongsideDecodedPayload(t *testing.T) {
+	action := configuration.IncomingEvent
+	verifier := func(ctx context.Context, wh *webhooks.Webhook) error {
+		var raw webhooks.IncomingEvent
+		if err := json.Unmarshal(wh.RawPayload, &raw); err != nil {
+			return fmt.Errorf("invalid raw payload: %v", err)
+		}
+		if raw.Event.ID != wh.Payload.(*webhooks.IncomingEvent).Event.ID {
+			return fmt.Errorf("raw payload doesn't match decoded payload")
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().WithAction(action, verifier, "")
+	h := webhooks.NewWebhookHandler(cfg)
+	payload, err := os.ReadFile("./testdata/" + string(action) + ".json")
+	if err != nil {
+		t.Errorf("Missing test payload for action %v", action)
+		return
+	}
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(payload)))
+	if resp.Code != http.StatusOK {
+		t.Errorf("invalid code: %v, body: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestWebhookKeysAreMatchedCaseInsensitively(t *testing.T) {
+	action := configuration.IncomingEvent
+	verifier := func(ctx context.Context, wh *webhooks.Webhook) error {
+		if wh.WebhookID != "wh_id" || wh.Payload.(*webhooks.IncomingEvent).ChatID != "chat_id" {
+			return fmt.Errorf("invalid webhook: %+v", wh)
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().WithAction(action, verifier, "dummy_key")
+	h := webhooks.NewWebhookHandler(cfg)
+	body := `{"Webhook_ID": "wh_id", "Secret_Key": "dummy_key", "Action": "incoming_event", "Payload": {"chat_id": "chat_id"}}`
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBufferString(body)))
+	if resp.Code != http.StatusOK {
+		t.Errorf("invalid code: %v, body: %v", resp.Code, resp.Body)
+	}
+}
@@ -0,0 +1,108 @@
+package webhooks
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+)
+
+// PayloadFactory creates new, empty payload structure that webhook payload is decoded into.
+type PayloadFactory func() interface{}
+
+// PayloadRegistry maps webhook actions to their payload structures.
+//
+// It's the single place where webhook actions are bound to payload structures of this package.
+//
+// It's safe for concurrent use.
+type PayloadRegistry struct {
+	mu        sync.RWMutex
+	factories map[configuration.WebhookAction]PayloadFactory
+}
+
+// NewPayloadRegistry creates PayloadRegistry with payload structures of all webhook actions supported by this package.
+func NewPayloadRegistry() *PayloadRegistry {
+	return &PayloadRegistry{
+		factories: map[configuration.WebhookAction]PayloadFactory{
+			configuration.IncomingChat:                 func() interface{} { return &IncomingChat{} },
+			configuration.IncomingEvent:                func() interface{} { return &IncomingEvent{} },
+			configuration.EventUpdated:                 func() interface{} { return &EventUpdated{} },
+			configuration.IncomingRichMessagePostback:  func() interface{} { return &IncomingRichMessagePostback{} },
+			configuration.ChatDeactivated:              func() interface{} { return &ChatDeactivated{} },
+			configuration.ChatPropertiesUpdated:        func() interface{} { return &ChatPropertiesUpdated{} },
+			configuration.ThreadPropertiesUpdated:      func() interface{} { return &ThreadPropertiesUpdated{} },
+			configuration.ChatPropertiesDeleted:        func() interface{} { return &ChatPropertiesDeleted{} },
+			configuration.ThreadPropertiesDeleted:      func() interface{} { return &ThreadPropertiesDeleted{} },
+			configuration.UserAddedToChat:              func() interface{} { return &UserAddedToChat{} },
+			configuration.UserRemovedFromChat:          func() interface{} { return &UserRemovedFromChat{} },
+			configuration.ThreadTagged:                 func() interface{} { return &ThreadTagged{} },
+			configuration.ThreadUntagged:               func() interface{} { return &ThreadUntagged{} },
+			configuration.AgentCreated:                 func() interface{} { return &AgentCreated{} },
+			configuration.AgentUpdated:                 func() interface{} { return &AgentUpdated{} },
+			configuration.AgentDeleted:                 func() interface{} { return &AgentDeleted{} },
+			configuration.AgentSuspended:               func() interface{} { return &AgentSuspended{} },
+			configuration.AgentUnsuspended:             func() interface{} { return &AgentUnsuspended{} },
+			configuration.AgentApproved:                func() interface{} { return &AgentApproved{} },
+			configuration.EventsMarkedAsSeen:           func() interface{} { return &EventsMarkedAsSeen{} },
+			configuration.ChatAccessUpdated:            func() interface{} { return &ChatAccessUpdated{} },
+			configuration.EventPropertiesUpdated:       func() interface{} { return &EventPropertiesUpdated{} },
+			configuration.EventPropertiesDeleted:       func() interface{} { return &EventPropertiesDeleted{} },
+			configuration.RoutingStatusSet:             func() interface{} { return &RoutingStatusSet{} },
+			configuration.ChatTransferred:              func() interface{} { return &ChatTransferred{} },
+			configuration.IncomingCustomer:             func() interface{} { return &IncomingCustomer{} },
+			configuration.CustomerSessionFieldsUpdated: func() interface{} { return &CustomerSessionFieldsUpdated{} },
+			configuration.GroupCreated:                 func() interface{} { return &GroupCreated{} },
+			configuration.GroupUpdated:                 func() interface{} { return &GroupUpdated{} },
+			configuration.GroupDeleted:                 func() interface{} { return &GroupDeleted{} },
+			configuration.AutoAccessAdded:              func() interface{} { return &AutoAccessAdded{} },
+			configuration.AutoAccessUpdated:            func() interface{} { return &AutoAccessUpdated{} },
+			configuration.AutoAccessDeleted:            func() interface{} { return &AutoAccessDeleted{} },
+			configuration.BotCreated:                   func() interface{} { return &BotCreated{} },
+			configuration.BotUpdated:                   func() interface{} { return &BotUpdated{} },
+			configuration.BotDeleted:                   func() interface{} { return &BotDeleted{} },
+		},
+	}
+}
+
+// Register sets payload structure for given webhook action, eg. for action not supported by this package yet.
+// It overrides payload structure of already registered action.
+func (r *PayloadRegistry) Register(action configuration.WebhookAction, factory PayloadFactory) *PayloadRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[action] = factory
+	return r
+}
+
+// clone returns copy of the registry, which can be changed independently.
+func (r *PayloadRegistry) clone() *PayloadRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	factories := make(map[configuration.WebhookAction]PayloadFactory, len(r.factories))
+	for action, factory := range r.factories {
+		factories[action] = factory
+	}
+	return &PayloadRegistry{factories: factories}
+}
+
+// New creates new payload structure for given webhook action. It returns nil if action is not registered.
+func (r *PayloadRegistry) New(action configuration.WebhookAction) interface{} {
+	r.mu.RLock()
+	factory, exists := r.factories[action]
+	r.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+	return factory()
+}
+
+// Actions returns all actions with registered payload structure, sorted alphabetically.
+func (r *PayloadRegistry) Actions() []configuration.WebhookAction {
+	r.mu.RLock()
+	actions := make([]configuration.WebhookAction, 0, len(r.factories))
+	for action := range r.factories {
+		actions = append(actions, action)
+	}
+	r.mu.RUnlock()
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	return actions
+}
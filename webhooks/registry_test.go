@@ -0,0 +1,138 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+var futureWebhook = []byte(`{
+	"webhook_id": "wh",
+	"secret_key": "dummy_key",
+	"action": "chat_summarized",
+	"organization_id": "390e44e6-f1e6-0368c-z6ddb-74g14508c2ex",
+	"payload": {"chat_id": "PS0X0L086G", "summary": "Customer asked about pricing"},
+	"additional_data": {}
+}`)
+
+type chatSummarized struct {
+	ChatID  string `json:"chat_id"`
+	Summary string `json:"summary"`
+}
+
+func TestRawActionReceivesUndecodedPayload(t *testing.T) {
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		if wh.Payload != nil {
+			return errors.New("payload shouldn't be decoded")
+		}
+		var p chatSummarized
+		if err := json.Unmarshal(wh.RawPayload, &p); err != nil {
+			return err
+		}
+		if p.Summary != "Customer asked about pricing" {
+			return errors.New("invalid raw payload")
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().WithRawAction("chat_summarized", handler, "dummy_key")
+	h := webhooks.NewWebhookHandler(cfg)
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(futureWebhook)))
+	if resp.Code != http.StatusOK {
+		t.Errorf("invalid code: %v, body: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestFallbackActionHandlesWebhooksWithoutHandler(t *testing.T) {
+	var action string
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		action = wh.Action
+		return nil
+	}
+
+	cfg := webhooks.NewConfiguration().WithFallbackAction(handler, "other_dummy_key")
+	h := webhooks.NewWebhookHandler(cfg)
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(futureWebhook)))
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("invalid code: %v", resp.Code)
+	}
+
+	cfg = webhooks.NewConfiguration().WithFallbackAction(handler, "dummy_key")
+	h = webhooks.NewWebhookHandler(cfg)
+	resp = httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(futureWebhook)))
+	if resp.Code != http.StatusOK {
+		t.Errorf("invalid code: %v", resp.Code)
+	}
+	if action != "chat_summarized" {
+		t.Errorf("fallback handler not called: %v", action)
+	}
+}
+
+func TestUnknownActionIsRejectedWithoutPayloadType(t *testing.T) {
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error { return nil }
+	cfg := webhooks.NewConfiguration().WithAction("chat_summarized", handler, "")
+	h := webhooks.NewWebhookHandler(cfg)
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(futureWebhook)))
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("invalid code: %v", resp.Code)
+	}
+}
+
+func TestRegisteredPayloadTypeIsDecoded(t *testing.T) {
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		p, ok := wh.Payload.(*chatSummarized)
+		if !ok {
+			return errors.New("invalid payload type")
+		}
+		if p.ChatID != "PS0X0L086G" {
+			return errors.New("invalid payload")
+		}
+		return nil
+	}
+
+	registry := webhooks.NewPayloadRegistry().Register("chat_summarized", func() interface{} { return &chatSummarized{} })
+	for name, cfg := range map[string]*webhooks.Configuration{
+		"registry": webhooks.NewConfiguration().WithPayloadRegistry(registry),
+		"type":     webhooks.NewConfiguration().WithPayloadType("chat_summarized", func() interface{} { return &chatSummarized{} }),
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := webhooks.NewWebhookHandler(cfg.WithAction("chat_summarized", handler, ""))
+			resp := httptest.NewRecorder()
+			h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(futureWebhook)))
+			if resp.Code != http.StatusOK {
+				t.Errorf("invalid code: %v, body: %v", resp.Code, resp.Body)
+			}
+		})
+	}
+}
+
+// TestEveryActionHasPayloadType relies on configuration.WebhookActions listing all WebhookAction constants,
+// which is checked by configuration's TestWebhookActionsListsEveryConstant.
+func TestEveryActionHasPayloadType(t *testing.T) {
+	registry := webhooks.NewPayloadRegistry()
+	known := make(map[configuration.WebhookAction]bool)
+	for _, action := range configuration.WebhookActions() {
+		known[action] = true
+		if registry.New(action) == nil {
+			t.Errorf("missing payload type for action %v", action)
+		}
+		if _, exists := verifiers[action]; !exists {
+			t.Errorf("missing verifier for action %v", action)
+		}
+	}
+	for _, action := range registry.Actions() {
+		if !known[action] {
+			t.Errorf("payload type registered for unknown action %v", action)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/metrics"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+	"github.com/livechat/lc-sdk-go/v6/webhooks/webhooktest"
+)
+
+func TestStatsSinkReceivesWebhookStats(t *testing.T) {
+	var stats []metrics.WebhookStats
+	handlerErr := errors.New("handler failed")
+	cfg := webhooks.NewConfiguration().
+		WithAction(configuration.IncomingEvent, func(ctx context.Context, wh *webhooks.Webhook) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		}, webhooktest.DefaultSecretKey).
+		WithAction(configuration.IncomingChat, func(ctx context.Context, wh *webhooks.Webhook) error {
+			return handlerErr
+		}, webhooktest.DefaultSecretKey).
+		WithStatsSink(func(s metrics.WebhookStats) {
+			stats = append(stats, s)
+		})
+	h := webhooks.NewWebhookHandler(cfg)
+
+	incomingEvent, _ := webhooktest.Fixture(configuration.IncomingEvent)
+	incomingChat, _ := webhooktest.Fixture(configuration.IncomingChat)
+	invalidSecret, _ := webhooktest.NewWebhook(configuration.IncomingEvent, webhooks.IncomingEvent{}).WithSecretKey("invalid").Body()
+	for _, body := range [][]byte{incomingEvent, incomingChat, invalidSecret, []byte(`{"action": `)} {
+		h(httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(body)))
+	}
+
+	if len(stats) != 4 {
+		t.Fatalf("invalid number of stats: %v", len(stats))
+	}
+	if s := stats[0]; !s.Success || s.Action != "incoming_event" || s.HandlerExecutionTime < time.Millisecond {
+		t.Errorf("invalid stats of handled webhook: %+v", s)
+	}
+	if s := stats[1]; s.Success || !s.HandlerFailed || s.Action != "incoming_chat" || s.ErrorCode != string(webhooks.ErrCodeHandler) {
+		t.Errorf("invalid stats of failed webhook: %+v", s)
+	}
+	if s := stats[2]; s.Success || !s.SecretMismatch || s.HandlerExecutionTime != 0 {
+		t.Errorf("invalid stats of webhook with invalid secret: %+v", s)
+	}
+	if s := stats[3]; s.Success || !s.DecodeFailed || s.Action != "" {
+		t.Errorf("invalid stats of malformed webhook: %+v", s)
+	}
+}
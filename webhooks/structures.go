@@ -2,15 +2,42 @@ package webhooks
 
 import (
 	"encoding/json"
-	"fmt"
+	"sort"
 	"time"
 
 	"github.com/livechat/lc-sdk-go/v6/configuration"
-	"github.com/livechat/lc-sdk-go/v6/internal"
+	"github.com/livechat/lc-sdk-go/v6/objects"
 )
 
-// Properties represents LiveChat properties in form of property_namespace -> property -> value.
-type Properties map[string]map[string]interface{}
+// Structures shared with other packages, see package objects for their documentation.
+type (
+	Properties         = objects.Properties
+	User               = objects.User
+	Agent              = objects.Agent
+	Customer           = objects.Customer
+	Visit              = objects.Visit
+	Geolocation        = objects.Geolocation
+	Access             = objects.Access
+	Queue              = objects.Queue
+	Event              = objects.Event
+	FilledForm         = objects.FilledForm
+	Postback           = objects.Postback
+	Message            = objects.Message
+	SystemMessage      = objects.SystemMessage
+	File               = objects.File
+	RichMessage        = objects.RichMessage
+	RichMessageElement = objects.RichMessageElement
+	RichMessageButton  = objects.RichMessageButton
+	RichMessageImage   = objects.RichMessageImage
+	CustomEvent        = objects.CustomEvent
+	Form               = objects.Form
+	FormField          = objects.FormField
+	FormFieldOption    = objects.FormFieldOption
+	TypedEvent         = objects.TypedEvent
+)
+
+// ErrUnsupportedEventType is returned by Event's Decode function for events of types without dedicated structure.
+var ErrUnsupportedEventType = objects.ErrUnsupportedEventType
 
 // Webhook represents general webhook format.
 type Webhook struct {
@@ -21,6 +48,8 @@ type Webhook struct {
 	AdditionalData json.RawMessage `json:"additional_data"`
 	RawPayload     json.RawMessage `json:"payload"`
 	Payload        interface{}
+	// Additional is AdditionalData decoded by WebhookHandler.
+	Additional *AdditionalData `json:"-"`
 }
 
 // IncomingChat represents payload of incoming_chat webhook.
@@ -298,97 +327,16 @@ func (p *IncomingChat) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// User represents base of both Customer and Agent
-//
-// To get specific user type's structure, call Agent() or Customer() (based on Type value).
-type User struct {
-	ID             string    `json:"id"`
-	Type           string    `json:"type"`
-	Name           string    `json:"name"`
-	Avatar         string    `json:"avatar"`
-	Email          string    `json:"email"`
-	Present        bool      `json:"present"`
-	EventsSeenUpTo time.Time `json:"events_seen_up_to"`
-	userSpecific
-}
-
-type userSpecific struct {
-	RoutingStatus              json.RawMessage `json:"routing_status"`
-	LastVisit                  json.RawMessage `json:"last_visit"`
-	Statistics                 json.RawMessage `json:"statistics"`
-	AgentLastEventCreatedAt    json.RawMessage `json:"agent_last_event_created_at"`
-	CustomerLastEventCreatedAt json.RawMessage `json:"customer_last_event_created_at"`
-	SessionFields              json.RawMessage `json:"session_fields"`
-	Followed                   json.RawMessage `json:"followed"`
-	Online                     json.RawMessage `json:"online"`
-	State                      json.RawMessage `json:"state"`
-	GroupIDs                   json.RawMessage `json:"group_ids"`
-	EmailVerified              json.RawMessage `json:"email_verified"`
-	CreatedAt                  json.RawMessage `json:"created_at"`
-	Visibility                 json.RawMessage `json:"visibility"`
-}
-
-// Agent function converts User object to Agent object if User's Type is "agent".
-// If Type is different or User is malformed, then it returns nil.
-func (u *User) Agent() *Agent {
-	if u.Type != "agent" {
-		return nil
-	}
-	var a Agent
-
-	a.User = u
-	if err := internal.UnmarshalOptionalRawField(u.RoutingStatus, &a.RoutingStatus); err != nil {
-		return nil
+// MarshalJSON implements json.Marshaler interface for IncomingChat.
+func (p IncomingChat) MarshalJSON() ([]byte, error) {
+	chat := p.Chat
+	if chat.Thread == nil && len(chat.Threads) > 0 {
+		chat.Thread = &chat.Threads[len(chat.Threads)-1]
 	}
-	if err := internal.UnmarshalOptionalRawField(u.Visibility, &a.Visibility); err != nil {
-		return nil
-	}
-	return &a
-}
-
-// Customer function converts User object to Customer object if User's Type is "customer".
-// If Type is different or User is malformed, then it returns nil.
-func (u *User) Customer() *Customer {
-	if u.Type != "customer" {
-		return nil
-	}
-	var c Customer
-
-	c.User = u
-	if err := json.Unmarshal(u.LastVisit, &c.LastVisit); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.Statistics, &c.Statistics); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.AgentLastEventCreatedAt, &c.AgentLastEventCreatedAt); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.CustomerLastEventCreatedAt, &c.CustomerLastEventCreatedAt); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.EmailVerified, &c.EmailVerified); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.CreatedAt, &c.CreatedAt); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.Followed, &c.Followed); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.Online, &c.Online); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.State, &c.State); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.SessionFields, &c.SessionFields); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(u.GroupIDs, &c.GroupIDs); err != nil {
-		return nil
-	}
-	return &c
+	chat.Threads = nil
+	return json.Marshal(struct {
+		Chat Chat `json:"chat"`
+	}{chat})
 }
 
 // Chat represents LiveChat chat.
@@ -458,11 +406,34 @@ func (c *Chat) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// Queue represents position of a thread in a queue
-type Queue struct {
-	Position int       `json:"position"`
-	WaitTime int       `json:"wait_time"`
-	QueuedAt time.Time `json:"queued_at"`
+// MarshalJSON implements json.Marshaler interface for Chat.
+func (c Chat) MarshalJSON() ([]byte, error) {
+	ids := make([]string, 0, len(c.Agents)+len(c.Customers))
+	for id := range c.Agents {
+		ids = append(ids, id)
+	}
+	for id := range c.Customers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	users := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		if a, exists := c.Agents[id]; exists {
+			users = append(users, a)
+		}
+		if cu, exists := c.Customers[id]; exists {
+			users = append(users, cu)
+		}
+	}
+	return json.Marshal(struct {
+		ID         string        `json:"id,omitempty"`
+		Properties Properties    `json:"properties,omitempty"`
+		Access     *Access       `json:"access,omitempty"`
+		Thread     *Thread       `json:"thread,omitempty"`
+		Threads    []Thread      `json:"threads,omitempty"`
+		IsFollowed bool          `json:"is_followed,omitempty"`
+		Users      []interface{} `json:"users"`
+	}{c.ID, c.Properties, c.Access, c.Thread, c.Threads, c.IsFollowed, users})
 }
 
 // Thread represents LiveChat chat thread
@@ -484,312 +455,113 @@ type Thread struct {
 	QueuesDuration            *int       `json:"queues_duration,omitempty"`
 }
 
-// Access represents LiveChat chat and thread access
-type Access struct {
-	GroupIDs []int `json:"group_ids"`
-}
-
-// Agent represents LiveChat agent.
-type Agent struct {
-	*User
-	RoutingStatus string `json:"routing_status,omitempty"`
-	Visibility    string `json:"visibility,omitempty"`
-}
-
-// Visit contains information about particular customer's visit.
-type Visit struct {
-	IP          string `json:"ip"`
-	UserAgent   string `json:"user_agent"`
-	Geolocation struct {
-		Country     string `json:"country"`
-		CountryCode string `json:"country_code"`
-		Region      string `json:"region"`
-		City        string `json:"city"`
-		Timezone    string `json:"timezone"`
-	} `json:"geolocation"`
-	StartedAt time.Time `json:"started_at"`
-	EndedAt   time.Time `json:"ended_at"`
-	Referrer  string    `json:"referrer"`
-	LastPages []struct {
-		OpenedAt time.Time `json:"opened_at"`
-		URL      string    `json:"url"`
-		Title    string    `json:"title"`
-	} `json:"last_pages"`
-}
-
-// Customer represents LiveChat customer.
-type Customer struct {
-	*User
-	EmailVerified bool  `json:"email_verified"`
-	LastVisit     Visit `json:"last_visit"`
-	Statistics    struct {
-		VisitsCount            int `json:"visits_count"`
-		ThreadsCount           int `json:"threads_count"`
-		ChatsCount             int `json:"chats_count"`
-		PageViewsCount         int `json:"page_views_count"`
-		GreetingsShownCount    int `json:"greetings_shown_count"`
-		GreetingsAcceptedCount int `json:"greetings_accepted_count"`
-	} `json:"statistics"`
-	AgentLastEventCreatedAt    time.Time           `json:"agent_last_event_created_at"`
-	CustomerLastEventCreatedAt time.Time           `json:"customer_last_event_created_at"`
-	CreatedAt                  time.Time           `json:"created_at"`
-	SessionFields              []map[string]string `json:"session_fields"`
-	Followed                   bool                `json:"followed"`
-	Online                     bool                `json:"online"`
-	State                      string              `json:"state"`
-	GroupIDs                   []int               `json:"group_ids"`
-}
-
-// ValidateEvent checks if given interface resolves into supported event type
-func ValidateEvent(e interface{}) error {
-	switch v := e.(type) {
-	case *Event:
-	case *File:
-	case *Message:
-	case *RichMessage:
-	case *SystemMessage:
-	case Event:
-	case File:
-	case Message:
-	case RichMessage:
-	case SystemMessage:
-	default:
-		return fmt.Errorf("event type %T not supported", v)
-	}
+// restrictedAccess is value of objects.Thread's RestrictedAccess describing thread with restricted access.
+const restrictedAccess = "true"
 
-	return nil
-}
-
-type eventSpecific struct {
-	Text              json.RawMessage `json:"text"`
-	TextVars          json.RawMessage `json:"text_vars"`
-	Fields            json.RawMessage `json:"fields"`
-	ContentType       json.RawMessage `json:"content_type"`
-	Name              json.RawMessage `json:"name"`
-	URL               json.RawMessage `json:"url"`
-	ThumbnailURL      json.RawMessage `json:"thumbnail_url"`
-	Thumbnail2xURL    json.RawMessage `json:"thumbnail2x_url"`
-	Width             json.RawMessage `json:"width"`
-	Height            json.RawMessage `json:"height"`
-	Size              json.RawMessage `json:"size"`
-	TemplateID        json.RawMessage `json:"template_id"`
-	Elements          json.RawMessage `json:"elements"`
-	Postback          json.RawMessage `json:"postback"`
-	AlternativeText   json.RawMessage `json:"alternative_text"`
-	SystemMessageType json.RawMessage `json:"system_message_type"`
-}
-
-// Event represents base of all LiveChat chat events.
+// Object converts Thread to objects.Thread used by Agent and Customer Chat API clients.
 //
-// To get specific event type's structure, call appropriate function based on Event's Type.
-type Event struct {
-	ID         string     `json:"id,omitempty"`
-	CustomID   string     `json:"custom_id,omitempty"`
-	CreatedAt  time.Time  `json:"created_at"`
-	AuthorID   string     `json:"author_id"`
-	Properties Properties `json:"properties,omitempty"`
-	Visibility string     `json:"visibility,omitempty"`
-	Type       string     `json:"type,omitempty"`
-	eventSpecific
-}
-
-// FilledForm represents LiveChat filled form event.
-type FilledForm struct {
-	Fields []struct {
-		ID    string `json:"id"`
-		Label string `json:"label"`
-		Type  string `json:"type"`
-		Value string `json:"value"`
-	} `json:"fields"`
-	Event
-}
-
-// FilledForm function converts Event object to FilledForm object if Event's Type is "filled_form".
-// If Type is different or Event is malformed, then it returns nil.
-func (e *Event) FilledForm() *FilledForm {
-	if e.Type != "filled_form" {
-		return nil
-	}
-	var f FilledForm
-
-	f.Event = *e
-	if err := json.Unmarshal(e.Fields, &f.Fields); err != nil {
-		return nil
-	}
-	return &f
+// Webhooks describe restricted access with a flag, so RestrictedAccess is set to "true" for threads
+// with restricted access and left empty otherwise.
+func (t Thread) Object() objects.Thread {
+	ot := objects.Thread{
+		ID:                        t.ID,
+		Active:                    t.Active,
+		UserIDs:                   t.UserIDs,
+		Properties:                t.Properties,
+		Access:                    t.Access,
+		Tags:                      t.Tags,
+		Events:                    t.Events,
+		PreviousThreadID:          t.PreviousThreadID,
+		NextThreadID:              t.NextThreadID,
+		CreatedAt:                 t.CreatedAt,
+		PreviousAccesibleThreadID: t.PreviousAccesibleThreadID,
+		NextAccessibleThreadID:    t.NextAccessibleThreadID,
+		Queue:                     t.Queue,
+		QueuesDuration:            t.QueuesDuration,
+	}
+	if t.RestrictedAccess {
+		ot.RestrictedAccess = restrictedAccess
+	}
+	return ot
+}
+
+// ThreadFromObject converts objects.Thread used by Agent and Customer Chat API clients to Thread.
+// Thread has restricted access if RestrictedAccess of given thread is not empty.
+func ThreadFromObject(ot objects.Thread) Thread {
+	return Thread{
+		ID:                        ot.ID,
+		Active:                    ot.Active,
+		UserIDs:                   ot.UserIDs,
+		RestrictedAccess:          ot.RestrictedAccess != "",
+		Properties:                ot.Properties,
+		Access:                    ot.Access,
+		Tags:                      ot.Tags,
+		Events:                    ot.Events,
+		PreviousThreadID:          ot.PreviousThreadID,
+		NextThreadID:              ot.NextThreadID,
+		CreatedAt:                 ot.CreatedAt,
+		PreviousAccesibleThreadID: ot.PreviousAccesibleThreadID,
+		NextAccessibleThreadID:    ot.NextAccessibleThreadID,
+		Queue:                     ot.Queue,
+		QueuesDuration:            ot.QueuesDuration,
+	}
+}
+
+// Object converts Chat to objects.Chat used by Agent and Customer Chat API clients.
+// Its threads are converted with Thread's Object function.
+func (c Chat) Object() objects.Chat {
+	oc := objects.Chat{
+		ID:         c.ID,
+		Properties: c.Properties,
+		Access:     c.Access,
+		IsFollowed: c.IsFollowed,
+		Agents:     c.Agents,
+		Customers:  c.Customers,
+	}
+	if c.Thread != nil {
+		t := c.Thread.Object()
+		oc.Thread = &t
+	}
+	for _, t := range c.Threads {
+		oc.Threads = append(oc.Threads, t.Object())
+	}
+	return oc
+}
+
+// ChatFromObject converts objects.Chat used by Agent and Customer Chat API clients to Chat.
+// Its threads are converted with ThreadFromObject function.
+func ChatFromObject(oc objects.Chat) Chat {
+	c := Chat{
+		ID:         oc.ID,
+		Properties: oc.Properties,
+		Access:     oc.Access,
+		IsFollowed: oc.IsFollowed,
+		Agents:     oc.Agents,
+		Customers:  oc.Customers,
+	}
+	if oc.Thread != nil {
+		t := ThreadFromObject(*oc.Thread)
+		c.Thread = &t
+	}
+	for _, t := range oc.Threads {
+		c.Threads = append(c.Threads, ThreadFromObject(t))
+	}
+	return c
 }
 
-// Postback represents postback data in LiveChat message event.
-type Postback struct {
-	ID       string `json:"id"`
-	ThreadID string `json:"thread_id"`
-	EventID  string `json:"event_id"`
-	Type     string `json:"type,omitempty"`
-	Value    string `json:"value,omitempty"`
-}
-
-// Message represents LiveChat message event.
-type Message struct {
-	Event
-	Text     string    `json:"text,omitempty"`
-	Postback *Postback `json:"postback,omitempty"`
-}
-
-// Message function converts Event object to Message object if Event's Type is "message".
-// If Type is different or Event is malformed, then it returns nil.
-func (e *Event) Message() *Message {
-	if e.Type != "message" {
-		return nil
-	}
-	var m Message
-
-	m.Event = *e
-	if err := json.Unmarshal(e.Text, &m.Text); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.Postback, &m.Postback); err != nil {
-		return nil
-	}
-	return &m
+// ValidateEvent checks if given interface resolves into supported event type
+func ValidateEvent(e interface{}) error {
+	return objects.ValidateEvent(e)
 }
 
-// SystemMessage represents LiveChat system message event.
-type SystemMessage struct {
-	Event
-	SystemMessageType string            `json:"system_message_type"`
-	Text              string            `json:"text,omitempty"`
-	TextVars          map[string]string `json:"text_vars,omitempty"`
+// MarshalProperties converts struct with fields tagged with `lc:"namespace.name"` to Properties.
+// See objects.MarshalProperties for details.
+func MarshalProperties(v interface{}) (Properties, error) {
+	return objects.MarshalProperties(v)
 }
 
-// SystemMessage function converts Event object to SystemMessage object if Event's Type is "system_message".
-// If Type is different or Event is malformed, then it returns nil.
-func (e *Event) SystemMessage() *SystemMessage {
-	if e.Type != "system_message" {
-		return nil
-	}
-	var sm SystemMessage
-
-	sm.Event = *e
-	if err := json.Unmarshal(e.SystemMessageType, &sm.SystemMessageType); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.Text, &sm.Text); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.TextVars, &sm.TextVars); err != nil {
-		return nil
-	}
-	return &sm
-}
-
-// File represents LiveChat file event
-type File struct {
-	Event
-	ContentType     string `json:"content_type"`
-	Name            string `json:"name"`
-	URL             string `json:"url"`
-	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
-	Thumbnail2xURL  string `json:"thumbnail2x_url,omitempty"`
-	Width           int    `json:"width,omitempty"`
-	Height          int    `json:"height,omitempty"`
-	Size            int    `json:"size,omitempty"`
-	AlternativeText string `json:"alternative_text,omitempty"`
-}
-
-// File function converts Event object to File object if Event's Type is "file".
-// If Type is different or Event is malformed, then it returns nil.
-func (e *Event) File() *File {
-	if e.Type != "file" {
-		return nil
-	}
-	var f File
-
-	f.Event = *e
-	if err := json.Unmarshal(e.ContentType, &f.ContentType); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(e.Name, &f.Name); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(e.URL, &f.URL); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.ThumbnailURL, &f.ThumbnailURL); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.Thumbnail2xURL, &f.Thumbnail2xURL); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.Width, &f.Width); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.Height, &f.Height); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.Size, &f.Size); err != nil {
-		return nil
-	}
-	if err := internal.UnmarshalOptionalRawField(e.AlternativeText, &f.AlternativeText); err != nil {
-		return nil
-	}
-
-	return &f
-}
-
-// RichMessage represents LiveChat rich message event
-type RichMessage struct {
-	Event
-	TemplateID string               `json:"template_id"`
-	Elements   []RichMessageElement `json:"elements"`
-}
-
-// RichMessageElement represents element of LiveChat rich message
-type RichMessageElement struct {
-	Buttons  []RichMessageButton `json:"buttons"`
-	Title    string              `json:"title"`
-	Subtitle string              `json:"subtitle"`
-	Image    *RichMessageImage   `json:"image,omitempty"`
-}
-
-// RichMessageButton represents button in LiveChat rich message
-type RichMessageButton struct {
-	Text       string   `json:"text"`
-	Type       string   `json:"type"`
-	Value      string   `json:"value"`
-	UserIds    []string `json:"user_ids"`
-	PostbackID string   `json:"postback_id"`
-	// Allowed values: compact, full, tall
-	WebviewHeight string `json:"webview_height"`
-	// Allowed values: new, current
-	Target string `json:"target,omitempty"`
-}
-
-// RichMessageImage represents image in LiveChat rich message
-type RichMessageImage struct {
-	URL             string `json:"url"`
-	Name            string `json:"name,omitempty"`
-	ContentType     string `json:"content_type,omitempty"`
-	Size            int    `json:"size,omitempty"`
-	Width           int    `json:"width,omitempty"`
-	Height          int    `json:"height,omitempty"`
-	AlternativeText string `json:"alternative_text,omitempty"`
-}
-
-// RichMessage function converts Event object to RichMessage object if Event's Type is "rich_message".
-// If Type is different or Event is malformed, then it returns nil.
-func (e *Event) RichMessage() *RichMessage {
-	if e.Type != "rich_message" {
-		return nil
-	}
-	var rm RichMessage
-
-	rm.Event = *e
-	if err := json.Unmarshal(e.TemplateID, &rm.TemplateID); err != nil {
-		return nil
-	}
-	if err := json.Unmarshal(e.Elements, &rm.Elements); err != nil {
-		return nil
-	}
-
-	return &rm
+// UnmarshalProperties sets fields of struct pointed by v, tagged with `lc:"namespace.name"`, to values of Properties.
+// See objects.UnmarshalProperties for details.
+func UnmarshalProperties(p Properties, v interface{}) error {
+	return objects.UnmarshalProperties(p, v)
 }
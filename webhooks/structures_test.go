@@ -0,0 +1,100 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/agent"
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+	"github.com/livechat/lc-sdk-go/v6/webhooks/webhooktest"
+)
+
+func TestChatConversion(t *testing.T) {
+	event := &agent.Event{ID: "event_id", Type: "message"}
+	chat := agent.Chat{
+		ID:     "chat_id",
+		Thread: &agent.Thread{ID: "thread_id", RestrictedAccess: "some_reason", Events: []*agent.Event{event}},
+		Threads: []agent.Thread{
+			{ID: "previous_thread_id"},
+		},
+		Customers: map[string]*agent.Customer{"customer_id": {User: &agent.User{ID: "customer_id", Type: "customer"}}},
+	}
+
+	converted := webhooks.ChatFromObject(chat)
+	if converted.ID != "chat_id" || converted.Customers["customer_id"].ID != "customer_id" {
+		t.Errorf("invalid chat: %+v", converted)
+	}
+	if !converted.Thread.RestrictedAccess || converted.Thread.Events[0] != event {
+		t.Errorf("invalid thread: %+v", converted.Thread)
+	}
+	if converted.Threads[0].ID != "previous_thread_id" || converted.Threads[0].RestrictedAccess {
+		t.Errorf("invalid threads: %+v", converted.Threads)
+	}
+
+	restored := converted.Object()
+	if restored.Thread.ID != "thread_id" || restored.Thread.RestrictedAccess == "" {
+		t.Errorf("invalid restored thread: %+v", restored.Thread)
+	}
+	if restored.Threads[0].RestrictedAccess != "" {
+		t.Errorf("invalid restored threads: %+v", restored.Threads)
+	}
+}
+
+func TestIncomingCustomEvent(t *testing.T) {
+	var received *webhooks.CustomEvent
+	cfg := webhooks.NewConfiguration().WithAction(configuration.IncomingEvent, func(ctx context.Context, wh *webhooks.Webhook) error {
+		received = wh.Payload.(*webhooks.IncomingEvent).Event.CustomEvent()
+		return nil
+	}, webhooktest.DefaultSecretKey)
+
+	payload := json.RawMessage(`{"chat_id": "chat_id", "thread_id": "thread_id", "event": {"id": "event_id", "type": "custom", "content": {"order_id": "1234"}}}`)
+	resp, err := webhooktest.NewWebhook(configuration.IncomingEvent, payload).Send(webhooks.NewWebhookHandler(cfg))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("webhook failed: %v, %v", resp, err)
+	}
+
+	var content map[string]string
+	if received == nil || received.UnmarshalContent(&content) != nil || content["order_id"] != "1234" {
+		t.Errorf("invalid custom event: %+v", received)
+	}
+}
+
+func TestUnmarshalWebhookProperties(t *testing.T) {
+	var payload webhooks.ChatPropertiesUpdated
+	body, _ := webhooktest.Fixture(configuration.ChatPropertiesUpdated)
+	var wh struct {
+		Payload *webhooks.ChatPropertiesUpdated `json:"payload"`
+	}
+	wh.Payload = &payload
+	if err := json.Unmarshal(body, &wh); err != nil {
+		t.Fatalf("couldn't unmarshal webhook: %v", err)
+	}
+
+	var rating struct {
+		Score   int    `lc:"rating.score"`
+		Comment string `lc:"rating.comment"`
+	}
+	if err := webhooks.UnmarshalProperties(payload.Properties, &rating); err != nil {
+		t.Fatalf("UnmarshalProperties failed: %v", err)
+	}
+	if rating.Score != 1 || rating.Comment != "Very good, veeeery good" {
+		t.Errorf("invalid rating: %+v", rating)
+	}
+}
+
+func TestWebhooktestFixturesMatchTestdata(t *testing.T) {
+	for _, action := range configuration.WebhookActions() {
+		testdata, err := os.ReadFile("./testdata/" + string(action) + ".json")
+		if err != nil {
+			t.Fatalf("Missing test payload for action %v", action)
+		}
+		fixture, err := webhooktest.Fixture(action)
+		if err != nil || string(fixture) != string(testdata) {
+			t.Errorf("fixture of %v differs from test payload: %v", action, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+)
+
+var (
+	// ErrSubscriptionFull is returned when webhook cannot be delivered to Subscription with
+	// BackpressureReject mode, because its buffer is full.
+	ErrSubscriptionFull = errors.New("subscription buffer is full")
+	// ErrSubscriptionClosed is returned when webhook is delivered to closed Subscription.
+	ErrSubscriptionClosed = errors.New("subscription is closed")
+)
+
+// BackpressureMode defines Subscription's behavior when its buffer is full.
+type BackpressureMode int
+
+// Possible values of BackpressureMode.
+const (
+	// BackpressureBlock makes WebhookHandler wait until there is space in the buffer,
+	// webhook's request is cancelled or Subscription is closed.
+	BackpressureBlock BackpressureMode = iota
+	// BackpressureDropOldest makes Subscription discard the oldest buffered webhook to make space for the new one.
+	BackpressureDropOldest
+	// BackpressureReject makes WebhookHandler respond with 503 Service Unavailable, so that LiveChat retries the webhook later.
+	BackpressureReject
+)
+
+// SubscriptionOptions configures Subscription.
+type SubscriptionOptions struct {
+	// BufferSize is the size of Subscription's channel. Defaults to 100.
+	BufferSize int
+	// Backpressure defines behavior when the buffer is full. Defaults to BackpressureBlock.
+	Backpressure BackpressureMode
+}
+
+// Subscription delivers decoded webhooks on a buffered channel, see Configuration.WithSubscription.
+//
+// Webhook is considered processed (and responded with 200 OK) once it's put into the buffer.
+type Subscription struct {
+	webhooks     chan *Webhook
+	backpressure BackpressureMode
+	dropped      uint64
+
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSubscription creates Subscription with given options. If opts is nil, defaults are used.
+func NewSubscription(opts *SubscriptionOptions) *Subscription {
+	bufferSize := 100
+	backpressure := BackpressureBlock
+	if opts != nil {
+		if opts.BufferSize > 0 {
+			bufferSize = opts.BufferSize
+		}
+		backpressure = opts.Backpressure
+	}
+	return &Subscription{
+		webhooks:     make(chan *Webhook, bufferSize),
+		backpressure: backpressure,
+		done:         make(chan struct{}),
+	}
+}
+
+// WithSubscription allows to deliver webhooks with given actions to Subscription instead of a Handler.
+func (cfg *Configuration) WithSubscription(sub *Subscription, secretKey string, actions ...configuration.WebhookAction) *Configuration {
+	for _, action := range actions {
+		cfg.WithAction(action, sub.Handle, secretKey)
+	}
+	return cfg
+}
+
+// Webhooks returns channel of delivered webhooks. The channel is closed by Close.
+func (s *Subscription) Webhooks() <-chan *Webhook {
+	return s.webhooks
+}
+
+// Dropped returns number of webhooks discarded in BackpressureDropOldest mode.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Handle implements Handler by putting webhook into Subscription's buffer.
+func (s *Subscription) Handle(ctx context.Context, wh *Webhook) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return ErrSubscriptionClosed
+	}
+
+	switch s.backpressure {
+	case BackpressureDropOldest:
+		for {
+			select {
+			case s.webhooks <- wh:
+				return nil
+			default:
+			}
+			select {
+			case <-s.webhooks:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	case BackpressureReject:
+		select {
+		case s.webhooks <- wh:
+			return nil
+		default:
+			return ErrSubscriptionFull
+		}
+	default:
+		select {
+		case s.webhooks <- wh:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.done:
+			return ErrSubscriptionClosed
+		}
+	}
+}
+
+// Close stops accepting webhooks and closes the channel returned by Webhooks.
+//
+// Webhooks waiting in BackpressureBlock mode are rejected with ErrSubscriptionClosed, and new ones are responded
+// with 503 Service Unavailable. Webhooks already buffered remain available on the channel until it's drained.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		s.closed = true
+		close(s.webhooks)
+		s.mu.Unlock()
+	})
+}
@@ -0,0 +1,112 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+func sendChatWebhook(h http.HandlerFunc, chatID string) int {
+	resp := httptest.NewRecorder()
+	h(resp, httptest.NewRequest("POST", "https://example.com", bytes.NewBuffer(chatWebhookBody("incoming_event", chatID))))
+	return resp.Code
+}
+
+func TestSubscriptionDeliversWebhooks(t *testing.T) {
+	sub := webhooks.NewSubscription(nil)
+	cfg := webhooks.NewConfiguration().WithSubscription(sub, "", configuration.IncomingEvent)
+	h := webhooks.NewWebhookHandler(cfg)
+
+	if code := sendChatWebhook(h, "chat_1"); code != http.StatusOK {
+		t.Fatalf("invalid code: %v", code)
+	}
+	select {
+	case wh := <-sub.Webhooks():
+		if wh.ChatID() != "chat_1" {
+			t.Errorf("invalid webhook: %v", wh.ChatID())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestSubscriptionBlocksUntilBufferHasSpace(t *testing.T) {
+	sub := webhooks.NewSubscription(&webhooks.SubscriptionOptions{BufferSize: 1})
+	h := webhooks.NewWebhookHandler(webhooks.NewConfiguration().WithSubscription(sub, "", configuration.IncomingEvent))
+	sendChatWebhook(h, "chat_1")
+
+	codes := make(chan int)
+	go func() { codes <- sendChatWebhook(h, "chat_2") }()
+	select {
+	case <-codes:
+		t.Fatal("webhook should wait for space in the buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-sub.Webhooks()
+	if code := <-codes; code != http.StatusOK {
+		t.Errorf("invalid code: %v", code)
+	}
+	if wh := <-sub.Webhooks(); wh.ChatID() != "chat_2" {
+		t.Errorf("invalid webhook: %v", wh.ChatID())
+	}
+}
+
+func TestSubscriptionDropsOldestWebhooks(t *testing.T) {
+	sub := webhooks.NewSubscription(&webhooks.SubscriptionOptions{BufferSize: 2, Backpressure: webhooks.BackpressureDropOldest})
+	h := webhooks.NewWebhookHandler(webhooks.NewConfiguration().WithSubscription(sub, "", configuration.IncomingEvent))
+	for _, chatID := range []string{"chat_1", "chat_2", "chat_3"} {
+		if code := sendChatWebhook(h, chatID); code != http.StatusOK {
+			t.Errorf("invalid code: %v", code)
+		}
+	}
+	if sub.Dropped() != 1 {
+		t.Errorf("invalid number of dropped webhooks: %v", sub.Dropped())
+	}
+	if wh := <-sub.Webhooks(); wh.ChatID() != "chat_2" {
+		t.Errorf("oldest webhook should be dropped, got: %v", wh.ChatID())
+	}
+}
+
+func TestSubscriptionRejectsWebhooksWhenFull(t *testing.T) {
+	sub := webhooks.NewSubscription(&webhooks.SubscriptionOptions{BufferSize: 1, Backpressure: webhooks.BackpressureReject})
+	h := webhooks.NewWebhookHandler(webhooks.NewConfiguration().WithSubscription(sub, "", configuration.IncomingEvent))
+	sendChatWebhook(h, "chat_1")
+	if code := sendChatWebhook(h, "chat_2"); code != http.StatusServiceUnavailable {
+		t.Errorf("invalid code: %v", code)
+	}
+}
+
+func TestClosedSubscription(t *testing.T) {
+	sub := webhooks.NewSubscription(&webhooks.SubscriptionOptions{BufferSize: 1})
+	h := webhooks.NewWebhookHandler(webhooks.NewConfiguration().WithSubscription(sub, "", configuration.IncomingEvent))
+	sendChatWebhook(h, "chat_1")
+
+	codes := make(chan int)
+	go func() { codes <- sendChatWebhook(h, "chat_2") }()
+	time.Sleep(10 * time.Millisecond)
+	sub.Close()
+	if code := <-codes; code != http.StatusServiceUnavailable {
+		t.Errorf("blocked webhook should be rejected, got: %v", code)
+	}
+	if code := sendChatWebhook(h, "chat_3"); code != http.StatusServiceUnavailable {
+		t.Errorf("invalid code: %v", code)
+	}
+
+	var delivered []string
+	for wh := range sub.Webhooks() {
+		delivered = append(delivered, wh.ChatID())
+	}
+	if len(delivered) != 1 || delivered[0] != "chat_1" {
+		t.Errorf("buffered webhooks should remain available: %v", delivered)
+	}
+	if err := sub.Handle(context.Background(), &webhooks.Webhook{}); err != webhooks.ErrSubscriptionClosed {
+		t.Errorf("invalid error: %v", err)
+	}
+}
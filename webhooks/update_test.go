@@ -0,0 +1,118 @@
+package webhooks_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+	"github.com/livechat/lc-sdk-go/v6/webhooks/webhooktest"
+)
+
+func noopHandler(ctx context.Context, wh *webhooks.Webhook) error { return nil }
+
+func TestActionsCanBeChangedAtRuntime(t *testing.T) {
+	cfg := webhooks.NewConfiguration()
+	h := webhooks.NewWebhookHandler(cfg)
+	body, _ := webhooktest.Fixture(configuration.IncomingEvent)
+
+	if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+
+	cfg.WithAction(configuration.IncomingEvent, noopHandler, "other_key")
+	if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+
+	cfg.WithSecretKey(configuration.IncomingEvent, webhooktest.DefaultSecretKey)
+	if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusOK {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+
+	cfg.RemoveAction(configuration.IncomingEvent)
+	if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+}
+
+func TestUpdateIsAppliedAtomically(t *testing.T) {
+	cfg := webhooks.NewConfiguration().WithAction(configuration.IncomingEvent, noopHandler, "")
+	h := webhooks.NewWebhookHandler(cfg)
+	body, _ := webhooktest.Fixture(configuration.IncomingEvent)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusOK {
+					t.Errorf("webhook processed with partially applied update: %v", resp.StatusCode)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		cfg.Update(func(cfg *webhooks.Configuration) {
+			cfg.RemoveAction(configuration.IncomingEvent)
+			cfg.WithAction(configuration.IncomingEvent, noopHandler, "other_key")
+			cfg.WithSecretKey(configuration.IncomingEvent, webhooktest.DefaultSecretKey)
+		})
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestUpdateChangesFallbackAndSecretResolver(t *testing.T) {
+	cfg := webhooks.NewConfiguration()
+	h := webhooks.NewWebhookHandler(cfg)
+	body, _ := webhooktest.Fixture(configuration.IncomingEvent)
+
+	cfg.Update(func(cfg *webhooks.Configuration) {
+		cfg.WithFallbackAction(noopHandler, "other_key").
+			WithSecretResolver(func(ctx context.Context, wh *webhooks.Webhook) ([]string, error) {
+				return []string{webhooktest.DefaultSecretKey}, nil
+			})
+	})
+	if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusOK {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+}
+
+func TestUpdateStagesPayloadTypes(t *testing.T) {
+	registry := webhooks.NewPayloadRegistry()
+	handler := func(ctx context.Context, wh *webhooks.Webhook) error {
+		if _, ok := wh.Payload.(*chatSummarized); !ok {
+			return fmt.Errorf("invalid payload type: %T", wh.Payload)
+		}
+		return nil
+	}
+	cfg := webhooks.NewConfiguration().WithPayloadRegistry(registry)
+	h := webhooks.NewWebhookHandler(cfg)
+
+	cfg.Update(func(staged *webhooks.Configuration) {
+		staged.WithAction("chat_summarized", handler, "")
+		staged.WithPayloadType("chat_summarized", func() interface{} { return &chatSummarized{} })
+		if registry.New("chat_summarized") != nil {
+			t.Error("payload type shouldn't be registered before update is applied")
+		}
+	})
+	if registry.New("chat_summarized") != nil {
+		t.Error("registry of the original configuration shouldn't be changed")
+	}
+	if resp := webhooktest.Send(h, futureWebhook); resp.StatusCode != http.StatusOK {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+}
@@ -0,0 +1,213 @@
+// Package webhooktest implements utilities for testing LiveChat webhook handlers.
+//
+// It allows to build webhook bodies for every action from typed payloads, send them to http.Handler
+// and record invocations of webhook handlers:
+//
+//	rec := webhooktest.NewRecorder()
+//	cfg := webhooks.NewConfiguration().WithAction(configuration.IncomingEvent, rec.Handler(handler), webhooktest.DefaultSecretKey)
+//	resp, err := webhooktest.NewWebhook(configuration.IncomingEvent, &webhooks.IncomingEvent{ChatID: "PS0X0L086G"}).
+//		Send(webhooks.NewWebhookHandler(cfg))
+//
+// Realistic sample bodies of all webhooks are available with Fixture.
+package webhooktest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+)
+
+// Defaults of webhooks built with NewWebhook. Fixtures use the same values.
+const (
+	DefaultWebhookID      = "1188f559c4bae6c4b9a87a1b32d78202"
+	DefaultSecretKey      = "dummy_key"
+	DefaultOrganizationID = "390e44e6-f1e6-0368c-z6ddb-74g14508c2ex"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixture returns realistic sample body of webhook with given action.
+func Fixture(action configuration.WebhookAction) ([]byte, error) {
+	body, err := fixtures.ReadFile("fixtures/" + string(action) + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("missing fixture for action %v", action)
+	}
+	return body, nil
+}
+
+// Webhook allows to build webhook bodies.
+type Webhook struct {
+	webhookID      string
+	secretKey      string
+	action         configuration.WebhookAction
+	organizationID string
+	payload        interface{}
+	additionalData map[string]interface{}
+	payloads       *webhooks.PayloadRegistry
+}
+
+// NewWebhook creates Webhook with given action and payload.
+//
+// Payload must be of type registered for the action in webhooks.PayloadRegistry (either value or pointer),
+// or json.RawMessage, which is sent as is for any action. The default registry is used, unless other one
+// is set with WithPayloadRegistry.
+func NewWebhook(action configuration.WebhookAction, payload interface{}) *Webhook {
+	return &Webhook{
+		webhookID:      DefaultWebhookID,
+		secretKey:      DefaultSecretKey,
+		action:         action,
+		organizationID: DefaultOrganizationID,
+		payload:        payload,
+		additionalData: make(map[string]interface{}),
+	}
+}
+
+// WithWebhookID allows to override webhook's ID.
+func (w *Webhook) WithWebhookID(id string) *Webhook {
+	w.webhookID = id
+	return w
+}
+
+// WithSecretKey allows to override webhook's secret key.
+func (w *Webhook) WithSecretKey(secretKey string) *Webhook {
+	w.secretKey = secretKey
+	return w
+}
+
+// WithOrganizationID allows to override webhook's organization ID.
+func (w *Webhook) WithOrganizationID(id string) *Webhook {
+	w.organizationID = id
+	return w
+}
+
+// WithPayloadRegistry allows to check payload type against given registry, eg. the one passed to
+// webhooks.Configuration.WithPayloadRegistry with payload types of custom actions.
+func (w *Webhook) WithPayloadRegistry(r *webhooks.PayloadRegistry) *Webhook {
+	w.payloads = r
+	return w
+}
+
+// WithAdditionalData allows to attach additional data with given name, eg. configuration.ChatPropertiesData.
+func (w *Webhook) WithAdditionalData(name string, value interface{}) *Webhook {
+	w.additionalData[name] = value
+	return w
+}
+
+// Body returns webhook's body.
+func (w *Webhook) Body() ([]byte, error) {
+	if _, raw := w.payload.(json.RawMessage); !raw {
+		payloads := w.payloads
+		if payloads == nil {
+			payloads = webhooks.NewPayloadRegistry()
+		}
+		expected := payloads.New(w.action)
+		if expected == nil {
+			return nil, fmt.Errorf("unsupported action %v", w.action)
+		}
+		t := reflect.TypeOf(w.payload)
+		if t != reflect.TypeOf(expected) && t != reflect.TypeOf(expected).Elem() {
+			return nil, fmt.Errorf("invalid payload type %T for action %v, expected %T", w.payload, w.action, expected)
+		}
+	}
+
+	body, err := json.Marshal(struct {
+		WebhookID      string                      `json:"webhook_id"`
+		SecretKey      string                      `json:"secret_key"`
+		Action         configuration.WebhookAction `json:"action"`
+		OrganizationID string                      `json:"organization_id"`
+		Payload        interface{}                 `json:"payload"`
+		AdditionalData map[string]interface{}      `json:"additional_data"`
+	}{w.webhookID, w.secretKey, w.action, w.organizationID, w.payload, w.additionalData})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal webhook: %v", err)
+	}
+	return body, nil
+}
+
+// Send sends webhook to given handler and returns its response.
+func (w *Webhook) Send(h http.Handler) (*http.Response, error) {
+	body, err := w.Body()
+	if err != nil {
+		return nil, err
+	}
+	return Send(h, body), nil
+}
+
+// Send sends webhook body to given handler and returns its response.
+func Send(h http.Handler, body []byte) *http.Response {
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/webhooks", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	return resp.Result()
+}
+
+// Invocation represents single webhook handler call recorded by Recorder.
+type Invocation struct {
+	Webhook *webhooks.Webhook
+	// Err is an error returned by the recorded handler.
+	Err error
+}
+
+// Recorder records invocations of webhook handlers.
+type Recorder struct {
+	mu          sync.Mutex
+	invocations []Invocation
+}
+
+// NewRecorder creates empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Handler returns webhooks.Handler that records invocations of given handler.
+// If handler is nil, returned webhooks.Handler only records webhooks.
+func (r *Recorder) Handler(handler webhooks.Handler) webhooks.Handler {
+	return func(ctx context.Context, wh *webhooks.Webhook) error {
+		var err error
+		if handler != nil {
+			err = handler(ctx, wh)
+		}
+		r.mu.Lock()
+		r.invocations = append(r.invocations, Invocation{Webhook: wh, Err: err})
+		r.mu.Unlock()
+		return err
+	}
+}
+
+// Invocations returns all recorded invocations, in order of their completion.
+func (r *Recorder) Invocations() []Invocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Invocation(nil), r.invocations...)
+}
+
+// Webhooks returns recorded webhooks with given action.
+func (r *Recorder) Webhooks(action configuration.WebhookAction) []*webhooks.Webhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var whs []*webhooks.Webhook
+	for _, i := range r.invocations {
+		if i.Webhook.Action == string(action) {
+			whs = append(whs, i.Webhook)
+		}
+	}
+	return whs
+}
+
+// Reset removes all recorded invocations.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.invocations = nil
+	r.mu.Unlock()
+}
@@ -0,0 +1,130 @@
+package webhooktest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/livechat/lc-sdk-go/v6/configuration"
+	"github.com/livechat/lc-sdk-go/v6/webhooks"
+	"github.com/livechat/lc-sdk-go/v6/webhooks/webhooktest"
+)
+
+func recordingHandler(rec *webhooktest.Recorder, handler webhooks.Handler) http.Handler {
+	cfg := webhooks.NewConfiguration()
+	for _, action := range configuration.WebhookActions() {
+		cfg.WithAction(action, rec.Handler(handler), webhooktest.DefaultSecretKey)
+	}
+	return webhooks.NewWebhookHandler(cfg)
+}
+
+func TestFixturesAreHandled(t *testing.T) {
+	rec := webhooktest.NewRecorder()
+	h := recordingHandler(rec, nil)
+	for _, action := range configuration.WebhookActions() {
+		body, err := webhooktest.Fixture(action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp := webhooktest.Send(h, body); resp.StatusCode != http.StatusOK {
+			t.Errorf("invalid code for %v: %v", action, resp.StatusCode)
+		}
+		if len(rec.Webhooks(action)) != 1 {
+			t.Errorf("handler not invoked for %v", action)
+		}
+	}
+}
+
+func TestWebhooksBuiltFromFixturePayloadsAreEquivalent(t *testing.T) {
+	rec := webhooktest.NewRecorder()
+	h := recordingHandler(rec, nil)
+	for _, action := range configuration.WebhookActions() {
+		t.Run(string(action), func(t *testing.T) {
+			rec.Reset()
+			body, _ := webhooktest.Fixture(action)
+			webhooktest.Send(h, body)
+			expected := rec.Invocations()[0].Webhook.Payload
+
+			resp, err := webhooktest.NewWebhook(action, expected).Send(h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("invalid code: %v", resp.StatusCode)
+			}
+			e, _ := json.Marshal(expected)
+			a, _ := json.Marshal(rec.Webhooks(action)[1].Payload)
+			if !bytes.Equal(a, e) {
+				t.Errorf("payload mismatch\nexpected: %s\nactual:   %s", e, a)
+			}
+		})
+	}
+}
+
+func TestBuildingWebhookWithInvalidPayloadType(t *testing.T) {
+	if _, err := webhooktest.NewWebhook(configuration.IncomingEvent, &webhooks.IncomingChat{}).Body(); err == nil {
+		t.Error("webhook with invalid payload type shouldn't be built")
+	}
+	if _, err := webhooktest.NewWebhook("chat_summarized", &webhooks.IncomingChat{}).Body(); err == nil {
+		t.Error("webhook with unsupported action shouldn't be built")
+	}
+	if _, err := webhooktest.NewWebhook("chat_summarized", json.RawMessage(`{}`)).Body(); err != nil {
+		t.Errorf("webhook with raw payload should be built: %v", err)
+	}
+}
+
+type chatSummarized struct {
+	ChatID  string `json:"chat_id"`
+	Summary string `json:"summary"`
+}
+
+func TestBuildingWebhookWithCustomPayloadRegistry(t *testing.T) {
+	payloads := webhooks.NewPayloadRegistry().
+		Register("chat_summarized", func() interface{} { return &chatSummarized{} })
+	rec := webhooktest.NewRecorder()
+	cfg := webhooks.NewConfiguration().
+		WithPayloadRegistry(payloads).
+		WithAction("chat_summarized", rec.Handler(nil), webhooktest.DefaultSecretKey)
+
+	wh := webhooktest.NewWebhook("chat_summarized", chatSummarized{ChatID: "PS0X0L086G", Summary: "Refund"})
+	if _, err := wh.Body(); err == nil {
+		t.Error("webhook of action missing in default registry shouldn't be built")
+	}
+	resp, err := wh.WithPayloadRegistry(payloads).Send(webhooks.NewWebhookHandler(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+	whs := rec.Webhooks("chat_summarized")
+	if len(whs) != 1 || whs[0].Payload.(*chatSummarized).Summary != "Refund" {
+		t.Errorf("invalid recorded webhooks: %+v", whs)
+	}
+}
+
+func TestRecorderRecordsHandlerErrors(t *testing.T) {
+	rec := webhooktest.NewRecorder()
+	h := recordingHandler(rec, func(ctx context.Context, wh *webhooks.Webhook) error {
+		return errors.New("handler failed")
+	})
+	wh := webhooktest.NewWebhook(configuration.IncomingEvent, webhooks.IncomingEvent{ChatID: "PS0X0L086G"}).
+		WithAdditionalData(configuration.ChatPresenceUserIDsData, []string{"bot_id"})
+	resp, err := wh.Send(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("invalid code: %v", resp.StatusCode)
+	}
+	invocations := rec.Invocations()
+	if len(invocations) != 1 || invocations[0].Err == nil {
+		t.Fatalf("invalid invocations: %v", invocations)
+	}
+	if present, _ := invocations[0].Webhook.IsUserPresent("bot_id"); !present {
+		t.Error("additional data not sent")
+	}
+}